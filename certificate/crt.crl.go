@@ -138,7 +138,7 @@ func (s *CrtCrl) Verify(ca *Crt) error {
 	return ca.certificate.CheckCRLSignature(s.crl)
 }
 
-func (s *CrtCrl) ToFile(path string, caCrt *Crt, caKey *RSAPrivate, thisUpdate, nextUpdate *time.Time) error {
+func (s *CrtCrl) ToFile(path string, caCrt *Crt, caKey PrivateKey, thisUpdate, nextUpdate *time.Time) error {
 	data, err := s.ToMemory(caCrt, caKey, thisUpdate, nextUpdate)
 	if err != nil {
 		return err
@@ -160,7 +160,7 @@ func (s *CrtCrl) ToFile(path string, caCrt *Crt, caKey *RSAPrivate, thisUpdate,
 	return err
 }
 
-func (s *CrtCrl) ToMemory(caCrt *Crt, caKey *RSAPrivate, thisUpdate, nextUpdate *time.Time) ([]byte, error) {
+func (s *CrtCrl) ToMemory(caCrt *Crt, caKey PrivateKey, thisUpdate, nextUpdate *time.Time) ([]byte, error) {
 	if caCrt == nil {
 		return nil, fmt.Errorf("invalid ca certificate")
 	}
@@ -170,8 +170,9 @@ func (s *CrtCrl) ToMemory(caCrt *Crt, caKey *RSAPrivate, thisUpdate, nextUpdate
 	if caKey == nil {
 		return nil, fmt.Errorf("invalid ca key")
 	}
-	if caKey.key == nil {
-		return nil, fmt.Errorf("invalid ca key")
+	signer, err := caKey.CryptoSigner()
+	if err != nil {
+		return nil, fmt.Errorf("invalid ca key: %v", err)
 	}
 
 	thisUpd := time.Now()
@@ -196,7 +197,7 @@ func (s *CrtCrl) ToMemory(caCrt *Crt, caKey *RSAPrivate, thisUpdate, nextUpdate
 		}
 	}
 
-	data, err := caCrt.certificate.CreateCRL(rand.Reader, caKey.key, s.crl.TBSCertList.RevokedCertificates, thisUpd, nextUpd)
+	data, err := caCrt.certificate.CreateCRL(rand.Reader, signer, s.crl.TBSCertList.RevokedCertificates, thisUpd, nextUpd)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,479 @@
+package certificate
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var (
+	oidChallengePassword = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 7}
+	oidExtensionRequest  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 14}
+	oidSubjectAltName    = asn1.ObjectIdentifier{2, 5, 29, 17}
+)
+
+// 证书请求(PKCS#10)，参考：RFC 2986
+type CrtCsr struct {
+	request *x509.CertificateRequest
+}
+
+func (s *CrtCsr) Request() *x509.CertificateRequest {
+	return s.request
+}
+
+// 证书请求中的公钥，可直接作为Crt.Create的公钥签发证书
+func (s *CrtCsr) CryptoPublicKey() (crypto.PublicKey, error) {
+	if s.request == nil {
+		return nil, fmt.Errorf("invalid certificate request")
+	}
+
+	return s.request.PublicKey, nil
+}
+
+func (s *CrtCsr) PublicKey() *RSAPublic {
+	if s.request == nil {
+		return nil
+	}
+
+	key, ok := s.request.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil
+	}
+
+	return &RSAPublic{key: key}
+}
+
+// 创建证书请求
+// template: 主题、使用者可选标识(Hosts)、扩展信息、签名算法及质询密码
+// privateKey: 证书私钥，用于请求的自签名，仅调用一次签名
+func (s *CrtCsr) Create(template *CrtTemplate, privateKey PrivateKey) error {
+	if template == nil {
+		return fmt.Errorf("invalid template: nil")
+	}
+	if privateKey == nil {
+		return fmt.Errorf("invalid private key: nil")
+	}
+	signer, err := privateKey.CryptoSigner()
+	if err != nil {
+		return err
+	}
+	algorithm, err := csrSignatureAlgorithmFor(signer.Public(), template.SignatureAlgorithm)
+	if err != nil {
+		return err
+	}
+
+	// 标准库不支持质询密码属性，因此自行构造CertificationRequestInfo
+	tbs, err := s.createInfo(template, signer.Public())
+	if err != nil {
+		return err
+	}
+	digest := tbs
+	if algorithm.hash != 0 {
+		hasher := algorithm.hash.New()
+		hasher.Write(tbs)
+		digest = hasher.Sum(nil)
+	}
+	var opts crypto.SignerOpts = algorithm.hash
+	if algorithm.pss {
+		opts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: algorithm.hash}
+	}
+	signature, err := signer.Sign(rand.Reader, digest, opts)
+	if err != nil {
+		return err
+	}
+	algorithmIdentifier, err := algorithm.identifier()
+	if err != nil {
+		return err
+	}
+
+	data, err := asn1.Marshal(csrSigned{
+		Info:      asn1.RawValue{FullBytes: tbs},
+		Algorithm: asn1.RawValue{FullBytes: algorithmIdentifier},
+		Signature: asn1.BitString{Bytes: signature, BitLength: len(signature) * 8},
+	})
+	if err != nil {
+		return err
+	}
+	request, err := x509.ParseCertificateRequest(data)
+	if err != nil {
+		return err
+	}
+	s.request = request
+
+	return nil
+}
+
+// 验证请求的自签名
+func (s *CrtCsr) Verify() error {
+	if s.request == nil {
+		return fmt.Errorf("invalid certificate request")
+	}
+
+	return s.request.CheckSignature()
+}
+
+func (s *CrtCsr) CommonName() string {
+	if s.request == nil {
+		return ""
+	}
+
+	return s.request.Subject.CommonName
+}
+
+// 质询密码，未设置时为空
+func (s *CrtCsr) ChallengePassword() string {
+	if s.request == nil {
+		return ""
+	}
+	tbs, attributes, err := parseCsrInfo(s.request.RawTBSCertificateRequest)
+	if err != nil || tbs == nil {
+		return ""
+	}
+	for _, item := range attributes {
+		attribute := &csrAttribute{}
+		_, err = asn1.Unmarshal(item, attribute)
+		if err != nil || !attribute.Type.Equal(oidChallengePassword) || len(attribute.Values) < 1 {
+			continue
+		}
+		password := ""
+		_, err = asn1.Unmarshal(attribute.Values[0].FullBytes, &password)
+		if err == nil {
+			return password
+		}
+	}
+
+	return ""
+}
+
+func (s *CrtCsr) FromFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return s.FromData(data)
+}
+
+// 加载证书请求，支持PEM及DER格式
+func (s *CrtCsr) FromData(data []byte) error {
+	der := data
+	block, _ := pem.Decode(data)
+	if block != nil {
+		der = block.Bytes
+	}
+	request, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		return fmt.Errorf("invalid certificate request: %v", err)
+	}
+	s.request = request
+
+	return nil
+}
+
+func (s *CrtCsr) ToFile(path string) error {
+	if s.request == nil {
+		return fmt.Errorf("invalid certificate request")
+	}
+
+	folder := filepath.Dir(path)
+	err := os.MkdirAll(folder, 0777)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return pem.Encode(file, &pem.Block{Type: "CERTIFICATE REQUEST", Bytes: s.request.Raw})
+}
+
+func (s *CrtCsr) ToMemory() ([]byte, error) {
+	if s.request == nil {
+		return nil, fmt.Errorf("invalid certificate request")
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: s.request.Raw}), nil
+}
+
+func (s *CrtCsr) ToDer() ([]byte, error) {
+	if s.request == nil {
+		return nil, fmt.Errorf("invalid certificate request")
+	}
+
+	return s.request.Raw, nil
+}
+
+type csrAttribute struct {
+	Type   asn1.ObjectIdentifier
+	Values []asn1.RawValue `asn1:"set"`
+}
+
+type csrInfo struct {
+	Version       int
+	Subject       asn1.RawValue
+	PublicKeyInfo asn1.RawValue
+	Attributes    asn1.RawValue
+}
+
+type csrSigned struct {
+	Info      asn1.RawValue
+	Algorithm asn1.RawValue
+	Signature asn1.BitString
+}
+
+// 解析CertificationRequestInfo，返回各属性的DER编码
+func parseCsrInfo(data []byte) (*csrInfo, [][]byte, error) {
+	info := &csrInfo{}
+	_, err := asn1.Unmarshal(data, info)
+	if err != nil {
+		return nil, nil, err
+	}
+	if info.Attributes.Class != asn1.ClassContextSpecific || info.Attributes.Tag != 0 {
+		return nil, nil, fmt.Errorf("invalid certificate request attributes")
+	}
+
+	attributes := make([][]byte, 0)
+	rest := info.Attributes.Bytes
+	for len(rest) > 0 {
+		item := asn1.RawValue{}
+		rest, err = asn1.Unmarshal(rest, &item)
+		if err != nil {
+			return nil, nil, err
+		}
+		attributes = append(attributes, item.FullBytes)
+	}
+
+	return info, attributes, nil
+}
+
+func (s *CrtCsr) createInfo(template *CrtTemplate, publicKey crypto.PublicKey) ([]byte, error) {
+	request := template.Request()
+	subject, err := asn1.Marshal(request.Subject.ToRDNSequence())
+	if err != nil {
+		return nil, err
+	}
+	publicKeyInfo, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := make([][]byte, 0)
+	extensions, err := csrExtensions(request)
+	if err != nil {
+		return nil, err
+	}
+	if len(extensions) > 0 {
+		attribute, err := asn1.Marshal(struct {
+			Type   asn1.ObjectIdentifier
+			Values [][]pkix.Extension `asn1:"set"`
+		}{
+			Type:   oidExtensionRequest,
+			Values: [][]pkix.Extension{extensions},
+		})
+		if err != nil {
+			return nil, err
+		}
+		attributes = append(attributes, attribute)
+	}
+	if len(template.ChallengePassword) > 0 {
+		value, err := csrDirectoryString(template.ChallengePassword)
+		if err != nil {
+			return nil, err
+		}
+		attribute, err := asn1.Marshal(csrAttribute{
+			Type:   oidChallengePassword,
+			Values: []asn1.RawValue{{FullBytes: value}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		attributes = append(attributes, attribute)
+	}
+	// DER编码的SET OF按编码值排序
+	sort.Slice(attributes, func(i, j int) bool {
+		return bytes.Compare(attributes[i], attributes[j]) < 0
+	})
+
+	return asn1.Marshal(csrInfo{
+		Version:       0,
+		Subject:       asn1.RawValue{FullBytes: subject},
+		PublicKeyInfo: asn1.RawValue{FullBytes: publicKeyInfo},
+		Attributes: asn1.RawValue{
+			Class:      asn1.ClassContextSpecific,
+			Tag:        0,
+			IsCompound: true,
+			Bytes:      bytes.Join(attributes, nil),
+		},
+	})
+}
+
+// 使用者可选名称(Hosts)及自定义扩展，规则同x509.CreateCertificateRequest
+func csrExtensions(request *x509.CertificateRequest) ([]pkix.Extension, error) {
+	extensions := make([]pkix.Extension, 0)
+	if len(request.DNSNames) > 0 || len(request.IPAddresses) > 0 {
+		specified := false
+		for _, extension := range request.ExtraExtensions {
+			if extension.Id.Equal(oidSubjectAltName) {
+				specified = true
+			}
+		}
+		if !specified {
+			names := make([]asn1.RawValue, 0)
+			for _, name := range request.DNSNames {
+				names = append(names, asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 2, Bytes: []byte(name)})
+			}
+			for _, ip := range request.IPAddresses {
+				address := ip.To4()
+				if address == nil {
+					address = ip
+				}
+				names = append(names, asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 7, Bytes: address})
+			}
+			value, err := asn1.Marshal(names)
+			if err != nil {
+				return nil, err
+			}
+			extensions = append(extensions, pkix.Extension{Id: oidSubjectAltName, Value: value})
+		}
+	}
+
+	return append(extensions, request.ExtraExtensions...), nil
+}
+
+// 质询密码(DirectoryString)，参考：RFC 2985 5.4.1，可打印字符时使用PrintableString，否则使用UTF8String
+func csrDirectoryString(value string) ([]byte, error) {
+	for _, c := range value {
+		if !isPrintableChar(c) {
+			return asn1.MarshalWithParams(value, "utf8")
+		}
+	}
+
+	return asn1.MarshalWithParams(value, "printable")
+}
+
+// PrintableString字符集：A-Z a-z 0-9 空格 ' ( ) + , - . / : = ?
+func isPrintableChar(c rune) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') ||
+		strings.ContainsRune(" '()+,-./:=?", c)
+}
+
+type csrSignatureAlgorithm struct {
+	algorithm x509.SignatureAlgorithm
+	publicKey x509.PublicKeyAlgorithm
+	oid       asn1.ObjectIdentifier
+	hash      crypto.Hash
+	pss       bool
+}
+
+var csrSignatureAlgorithms = []*csrSignatureAlgorithm{
+	{x509.SHA1WithRSA, x509.RSA, asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 5}, crypto.SHA1, false},
+	{x509.SHA256WithRSA, x509.RSA, asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 11}, crypto.SHA256, false},
+	{x509.SHA384WithRSA, x509.RSA, asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 12}, crypto.SHA384, false},
+	{x509.SHA512WithRSA, x509.RSA, asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 13}, crypto.SHA512, false},
+	{x509.SHA256WithRSAPSS, x509.RSA, oidSignatureRSAPSS, crypto.SHA256, true},
+	{x509.SHA384WithRSAPSS, x509.RSA, oidSignatureRSAPSS, crypto.SHA384, true},
+	{x509.SHA512WithRSAPSS, x509.RSA, oidSignatureRSAPSS, crypto.SHA512, true},
+	{x509.ECDSAWithSHA1, x509.ECDSA, asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 1}, crypto.SHA1, false},
+	{x509.ECDSAWithSHA256, x509.ECDSA, asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}, crypto.SHA256, false},
+	{x509.ECDSAWithSHA384, x509.ECDSA, asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 3}, crypto.SHA384, false},
+	{x509.ECDSAWithSHA512, x509.ECDSA, asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 4}, crypto.SHA512, false},
+	{x509.PureEd25519, x509.Ed25519, asn1.ObjectIdentifier{1, 3, 101, 112}, 0, false},
+}
+
+var (
+	oidSignatureRSAPSS = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 10}
+	oidMGF1            = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 8}
+	oidHashes          = map[crypto.Hash]asn1.ObjectIdentifier{
+		crypto.SHA256: {2, 16, 840, 1, 101, 3, 4, 2, 1},
+		crypto.SHA384: {2, 16, 840, 1, 101, 3, 4, 2, 2},
+		crypto.SHA512: {2, 16, 840, 1, 101, 3, 4, 2, 3},
+	}
+)
+
+// 签名算法标识，RSA使用NULL参数，RSA-PSS盐长度等于摘要长度，参考：RFC 4055 3.1
+func (s *csrSignatureAlgorithm) identifier() ([]byte, error) {
+	identifier := pkix.AlgorithmIdentifier{Algorithm: s.oid}
+	if s.pss {
+		hashAlgorithm := pkix.AlgorithmIdentifier{Algorithm: oidHashes[s.hash], Parameters: asn1.NullRawValue}
+		mgfParameters, err := asn1.Marshal(hashAlgorithm)
+		if err != nil {
+			return nil, err
+		}
+		parameters, err := asn1.Marshal(struct {
+			Hash       pkix.AlgorithmIdentifier `asn1:"explicit,tag:0"`
+			MGF        pkix.AlgorithmIdentifier `asn1:"explicit,tag:1"`
+			SaltLength int                      `asn1:"explicit,tag:2"`
+		}{
+			Hash:       hashAlgorithm,
+			MGF:        pkix.AlgorithmIdentifier{Algorithm: oidMGF1, Parameters: asn1.RawValue{FullBytes: mgfParameters}},
+			SaltLength: s.hash.Size(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		identifier.Parameters = asn1.RawValue{FullBytes: parameters}
+	} else if s.publicKey == x509.RSA {
+		identifier.Parameters = asn1.NullRawValue
+	}
+
+	return asn1.Marshal(identifier)
+}
+
+// 根据公钥类型确定签名算法，algorithm为空时使用与x509.CreateCertificateRequest相同的默认值
+func csrSignatureAlgorithmFor(publicKey crypto.PublicKey, algorithm x509.SignatureAlgorithm) (*csrSignatureAlgorithm, error) {
+	var keyAlgorithm x509.PublicKeyAlgorithm
+	defaultAlgorithm := x509.UnknownSignatureAlgorithm
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		keyAlgorithm = x509.RSA
+		defaultAlgorithm = x509.SHA256WithRSA
+	case *ecdsa.PublicKey:
+		keyAlgorithm = x509.ECDSA
+		switch key.Curve {
+		case elliptic.P256():
+			defaultAlgorithm = x509.ECDSAWithSHA256
+		case elliptic.P384():
+			defaultAlgorithm = x509.ECDSAWithSHA384
+		case elliptic.P521():
+			defaultAlgorithm = x509.ECDSAWithSHA512
+		default:
+			return nil, fmt.Errorf("not support elliptic curve")
+		}
+	case ed25519.PublicKey:
+		keyAlgorithm = x509.Ed25519
+		defaultAlgorithm = x509.PureEd25519
+	default:
+		return nil, fmt.Errorf("not support public key: %T", publicKey)
+	}
+	if algorithm == x509.UnknownSignatureAlgorithm {
+		algorithm = defaultAlgorithm
+	}
+
+	for _, item := range csrSignatureAlgorithms {
+		if item.algorithm == algorithm {
+			if item.publicKey != keyAlgorithm {
+				return nil, fmt.Errorf("signature algorithm %v does not match public key: %T", algorithm, publicKey)
+			}
+			return item, nil
+		}
+	}
+
+	return nil, fmt.Errorf("not support signature algorithm: %v", algorithm)
+}
@@ -1,6 +1,8 @@
 package certificate
 
 import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/tls"
@@ -61,8 +63,58 @@ func (s *Crt) PublicKey() *RSAPublic {
 	return &RSAPublic{key: key}
 }
 
-func (s *Crt) Create(template, parentTemplate *x509.Certificate, publicKey *RSAPublic, privateKey *RSAPrivate) error {
-	data, err := x509.CreateCertificate(rand.Reader, template, parentTemplate, publicKey.key, privateKey.key)
+func (s *Crt) ECDSAPublicKey() *ECDSAPublic {
+	if s.certificate == nil {
+		return nil
+	}
+	if s.certificate.PublicKey == nil {
+		return nil
+	}
+
+	key, ok := s.certificate.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return nil
+	}
+
+	return &ECDSAPublic{key: key}
+}
+
+func (s *Crt) Ed25519PublicKey() *Ed25519Public {
+	if s.certificate == nil {
+		return nil
+	}
+	if s.certificate.PublicKey == nil {
+		return nil
+	}
+
+	key, ok := s.certificate.PublicKey.(ed25519.PublicKey)
+	if !ok {
+		return nil
+	}
+
+	return &Ed25519Public{key: key}
+}
+
+// 创建证书
+// publicKey: 证书公钥
+// privateKey: 签发者私钥
+func (s *Crt) Create(template, parentTemplate *x509.Certificate, publicKey PublicKey, privateKey PrivateKey) error {
+	if publicKey == nil {
+		return fmt.Errorf("invalid public key: nil")
+	}
+	if privateKey == nil {
+		return fmt.Errorf("invalid private key: nil")
+	}
+	pub, err := publicKey.CryptoPublicKey()
+	if err != nil {
+		return err
+	}
+	signer, err := privateKey.CryptoSigner()
+	if err != nil {
+		return err
+	}
+
+	data, err := x509.CreateCertificate(rand.Reader, template, parentTemplate, pub, signer)
 	if err != nil {
 		return err
 	}
@@ -75,6 +127,34 @@ func (s *Crt) Create(template, parentTemplate *x509.Certificate, publicKey *RSAP
 	return nil
 }
 
+// 生成tls.Certificate，privateKey为证书对应的私钥，chain为附加的上级证书
+func (s *Crt) TlsCertificate(privateKey PrivateKey, chain ...*Crt) (*tls.Certificate, error) {
+	if s.certificate == nil {
+		return nil, fmt.Errorf("invalid certificate")
+	}
+	if privateKey == nil {
+		return nil, fmt.Errorf("invalid private key: nil")
+	}
+	signer, err := privateKey.CryptoSigner()
+	if err != nil {
+		return nil, err
+	}
+
+	cert := &tls.Certificate{
+		Certificate: [][]byte{s.certificate.Raw},
+		PrivateKey:  signer,
+		Leaf:        s.certificate,
+	}
+	for _, c := range chain {
+		if c == nil || c.certificate == nil {
+			return nil, fmt.Errorf("invalid chain certificate")
+		}
+		cert.Certificate = append(cert.Certificate, c.certificate.Raw)
+	}
+
+	return cert, nil
+}
+
 func (s *Crt) FromCertificate(certificate *x509.Certificate) {
 	s.certificate = certificate
 }
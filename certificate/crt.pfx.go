@@ -1,6 +1,8 @@
 package certificate
 
 import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/tls"
@@ -35,13 +37,45 @@ func (s *CrtPfx) PrivateKey() *RSAPrivate {
 	return &RSAPrivate{key: key}
 }
 
+func (s *CrtPfx) ECDSAPrivateKey() *ECDSAPrivate {
+	if s.tlsCertificate == nil {
+		return nil
+	}
+	if s.tlsCertificate.PrivateKey == nil {
+		return nil
+	}
+
+	key, ok := s.tlsCertificate.PrivateKey.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil
+	}
+
+	return &ECDSAPrivate{key: key}
+}
+
+func (s *CrtPfx) Ed25519PrivateKey() *Ed25519Private {
+	if s.tlsCertificate == nil {
+		return nil
+	}
+	if s.tlsCertificate.PrivateKey == nil {
+		return nil
+	}
+
+	key, ok := s.tlsCertificate.PrivateKey.(ed25519.PrivateKey)
+	if !ok {
+		return nil
+	}
+
+	return &Ed25519Private{key: key}
+}
+
 func (s *CrtPfx) PublicKey() *RSAPublic {
 	privateKey := s.PrivateKey()
 	if privateKey == nil {
 		return nil
 	}
 
-	publicKey, err := privateKey.Public()
+	publicKey, err := privateKey.PublicKey()
 	if err != nil {
 		return nil
 	}
@@ -106,7 +140,7 @@ func (s *CrtPfx) FromMemory(data []byte, password string) error {
 	return nil
 }
 
-func (s *CrtPfx) ToFile(path string, ca *Crt, privateKey *RSAPrivate, password string) error {
+func (s *CrtPfx) ToFile(path string, ca *Crt, privateKey PrivateKey, password string) error {
 	data, err := s.ToMemory(ca, privateKey, password)
 	if err != nil {
 		return err
@@ -128,10 +162,18 @@ func (s *CrtPfx) ToFile(path string, ca *Crt, privateKey *RSAPrivate, password s
 	return err
 }
 
-func (s *CrtPfx) ToMemory(ca *Crt, privateKey *RSAPrivate, password string) ([]byte, error) {
+// privateKey: 证书私钥，须为可导出的RSA、ECDSA或Ed25519私钥
+func (s *CrtPfx) ToMemory(ca *Crt, privateKey PrivateKey, password string) ([]byte, error) {
 	if s.certificate == nil {
 		return nil, fmt.Errorf("invalid certificate")
 	}
+	if privateKey == nil {
+		return nil, fmt.Errorf("invalid private key: nil")
+	}
+	signer, err := privateKey.CryptoSigner()
+	if err != nil {
+		return nil, err
+	}
 
-	return pkcs12.Encode(rand.Reader, privateKey.key, s.certificate, []*x509.Certificate{ca.certificate}, password)
+	return pkcs12.Encode(rand.Reader, signer, s.certificate, []*x509.Certificate{ca.certificate}, password)
 }
@@ -50,6 +50,13 @@ type CrtTemplate struct {
 
 	// 有效期(默认365)
 	ExpiredDays int64
+
+	// 签名算法，默认由签发者私钥决定
+	// 如RSA签发者可指定x509.SHA256WithRSAPSS使用RSASSA-PSS签名
+	SignatureAlgorithm x509.SignatureAlgorithm
+
+	// 质询密码(仅用于证书请求)，供CA撤销证书等操作时验证申请者身份
+	ChallengePassword string
 }
 
 func (s *CrtTemplate) Template() (*x509.Certificate, error) {
@@ -60,10 +67,6 @@ func (s *CrtTemplate) Template() (*x509.Certificate, error) {
 	}
 
 	isCA := false
-	commonName := s.CommonName
-	if commonName == "" {
-		commonName = s.OrganizationalUnit
-	}
 	keyUsage := x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature
 	extKeyUsage := []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
 	ips := make([]net.IP, 0)
@@ -82,30 +85,12 @@ func (s *CrtTemplate) Template() (*x509.Certificate, error) {
 		extKeyUsage = nil
 	} else if strings.ToLower(s.Organization) == "server" {
 		extKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
-		if len(s.Hosts) > 0 {
-			for _, h := range s.Hosts {
-				if ip := net.ParseIP(h); ip != nil {
-					ips = append(ips, ip)
-				} else {
-					dns = append(dns, h)
-				}
-			}
-		}
+		ips, dns = s.hosts()
 	}
 
 	template := &x509.Certificate{
 		SerialNumber: serialNumber,
-		Subject: pkix.Name{
-			Country:            []string{"CN"},
-			Organization:       []string{s.Organization},
-			OrganizationalUnit: []string{s.OrganizationalUnit},
-
-			Locality:      []string{s.Locality},
-			Province:      []string{s.Province},
-			StreetAddress: []string{s.StreetAddress},
-
-			CommonName: commonName,
-		},
+		Subject:      s.subject(),
 
 		NotBefore:             notBefore,
 		NotAfter:              notAfter,
@@ -119,7 +104,55 @@ func (s *CrtTemplate) Template() (*x509.Certificate, error) {
 		DNSNames:    dns,
 
 		ExtraExtensions: s.Extensions,
+
+		SignatureAlgorithm: s.SignatureAlgorithm,
 	}
 
 	return template, nil
 }
+
+// 证书请求模板，包含主题、使用者可选标识(Hosts)、扩展信息及签名算法
+func (s *CrtTemplate) Request() *x509.CertificateRequest {
+	ips, dns := s.hosts()
+
+	return &x509.CertificateRequest{
+		Subject:            s.subject(),
+		IPAddresses:        ips,
+		DNSNames:           dns,
+		ExtraExtensions:    s.Extensions,
+		SignatureAlgorithm: s.SignatureAlgorithm,
+	}
+}
+
+func (s *CrtTemplate) subject() pkix.Name {
+	commonName := s.CommonName
+	if commonName == "" {
+		commonName = s.OrganizationalUnit
+	}
+
+	return pkix.Name{
+		Country:            []string{"CN"},
+		Organization:       []string{s.Organization},
+		OrganizationalUnit: []string{s.OrganizationalUnit},
+
+		Locality:      []string{s.Locality},
+		Province:      []string{s.Province},
+		StreetAddress: []string{s.StreetAddress},
+
+		CommonName: commonName,
+	}
+}
+
+func (s *CrtTemplate) hosts() ([]net.IP, []string) {
+	ips := make([]net.IP, 0)
+	dns := make([]string, 0)
+	for _, h := range s.Hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			ips = append(ips, ip)
+		} else {
+			dns = append(dns, h)
+		}
+	}
+
+	return ips, dns
+}
@@ -1,9 +1,30 @@
 package certificate
 
 import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"github.com/csby/security/encoding"
+	"github.com/csby/security/hash"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -40,6 +61,958 @@ func TestCrt_Create(t *testing.T) {
 	}
 }
 
+func TestPemBundle(t *testing.T) {
+	caCrt, caPrivate, err := testCreateCA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverCrt, serverPrivate, err := testCreateServer(caCrt, caPrivate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bundle := &PemBundle{}
+	err = bundle.AddRSAPrivate(serverPrivate, "server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = bundle.AddCrt(&serverCrt.Crt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = bundle.AddCrt(caCrt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = bundle.AddRSAPrivate(caPrivate, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := bundle.ToMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := &PemBundle{}
+	err = loaded.FromData(append([]byte("comment\n"), data...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := []int{PemPrivateKeyEncrypted, PemCertificate, PemCertificate, PemPrivateKeyPkcs8}
+	if len(loaded.Items) != len(kinds) {
+		t.Fatalf("expected %d items, got %d", len(kinds), len(loaded.Items))
+	}
+	for i, kind := range kinds {
+		if loaded.Items[i].Kind != kind {
+			t.Errorf("item %d: expected kind %d, got %d", i, kind, loaded.Items[i].Kind)
+		}
+	}
+
+	crts, err := loaded.Certificates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(crts) != 2 || crts[0].SerialNumberString() != serverCrt.SerialNumberString() {
+		t.Fatal("certificate chain mismatch")
+	}
+	if err = crts[0].Verify(crts[1]); err != nil {
+		t.Error(err)
+	}
+
+	key, err := loaded.PrivateKey().RSAPrivate("server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.Key().N.Cmp(serverPrivate.Key().N) != 0 {
+		t.Error("private key mismatch")
+	}
+
+	// OpenSSL TRUSTED CERTIFICATE：证书之后附加信任设置(serverAuth)
+	trust, _ := hex.DecodeString("300c300a06082b06010505070301")
+	trusted := &pem.Block{Type: "TRUSTED CERTIFICATE", Bytes: append(append([]byte{}, caCrt.certificate.Raw...), trust...)}
+	item := &PemItem{Kind: PemKind(trusted), Block: trusted}
+	crt, err := item.Crt()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crt.SerialNumberString() != caCrt.SerialNumberString() {
+		t.Error("trusted certificate mismatch")
+	}
+}
+
+func TestCrt_ToJwk(t *testing.T) {
+	caCrt, caPrivate, err := testCreateCA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverCrt, _, err := testCreateServer(caCrt, caPrivate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwk, err := serverCrt.ToJwk(caCrt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jwk.X5c) != 2 || len(jwk.X5t) < 1 || len(jwk.X5tS256) < 1 {
+		t.Fatalf("invalid certificate parameters: %+v", jwk)
+	}
+
+	crt := &Crt{}
+	err = crt.FromJwk(jwk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crt.SerialNumberString() != serverCrt.SerialNumberString() {
+		t.Error("certificate mismatch")
+	}
+
+	// 公钥参数与证书不一致
+	caJwk, err := caCrt.ToJwk()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mismatch := *jwk
+	mismatch.N = caJwk.N
+	err = (&Crt{}).FromJwk(&mismatch)
+	if err == nil {
+		t.Error("jwk with mismatched public key should fail")
+	}
+	// 证书指纹不一致
+	mismatch = *jwk
+	mismatch.X5t = caJwk.X5t
+	err = (&Crt{}).FromJwk(&mismatch)
+	if err == nil {
+		t.Error("jwk with mismatched x5t should fail")
+	}
+	mismatch = *jwk
+	mismatch.X5tS256 = caJwk.X5tS256
+	err = (&Crt{}).FromJwk(&mismatch)
+	if err == nil {
+		t.Error("jwk with mismatched x5t#S256 should fail")
+	}
+}
+
+func TestLoader_FromFile(t *testing.T) {
+	caCrt, caPrivate, err := testCreateCA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = testCreateServer(caCrt, caPrivate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	folder := testFileFolder()
+
+	prompts := 0
+	loader := &Loader{
+		PasswordPrompt: func(description string) (string, error) {
+			prompts++
+			return "server", nil
+		},
+	}
+	cases := []struct {
+		file         string
+		format       string
+		certificates int
+		privateKeys  int
+		prompts      int
+	}{
+		{"ca.crt", LoadFormatPem, 1, 0, 0},
+		{"ca.key", LoadFormatPem, 0, 1, 0},
+		{"server.key", LoadFormatPem, 0, 1, 1},
+		{"server.pfx", LoadFormatPkcs12, 2, 1, 1},
+	}
+	for _, c := range cases {
+		prompts = 0
+		result, err := loader.FromFile(filepath.Join(folder, c.file))
+		if err != nil {
+			t.Fatalf("%s: %v", c.file, err)
+		}
+		if result.Format != c.format || len(result.Certificates) != c.certificates ||
+			len(result.PrivateKeys) != c.privateKeys || prompts != c.prompts {
+			t.Errorf("%s: unexpected result: format=%s, certificates=%d, private keys=%d, prompts=%d",
+				c.file, result.Format, len(result.Certificates), len(result.PrivateKeys), prompts)
+		}
+	}
+
+	// DER及base64编码
+	for format, data := range map[string][]byte{
+		LoadFormatDer:    caCrt.Certificate().Raw,
+		LoadFormatBase64: []byte(encoding.ToBase64MimeString(caCrt.Certificate().Raw)),
+	} {
+		result, err := (&Loader{}).FromData(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Format != format || len(result.Certificates) != 1 {
+			t.Errorf("unexpected result: format=%s, certificates=%d", result.Format, len(result.Certificates))
+		}
+	}
+
+	// BER编码(不定长、分段OCTET STRING)的PFX
+	pfx, err := ioutil.ReadFile(filepath.Join(folder, "server.pfx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes, err := (&encoding.Asn1Parser{}).Parse(pfx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ber := testToBer(nodes)
+	if bytes.Equal(ber, pfx) || !bytes.Contains(ber, []byte{0x24, 0x80}) {
+		t.Fatal("ber encoding expected")
+	}
+	result, err := (&Loader{Password: "server"}).FromData(ber)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Format != LoadFormatPkcs12 || len(result.Certificates) != 2 || len(result.PrivateKeys) != 1 {
+		t.Errorf("unexpected ber pfx result: format=%s, certificates=%d, private keys=%d",
+			result.Format, len(result.Certificates), len(result.PrivateKeys))
+	}
+
+	// 损坏的加密私钥不应作为密码错误反复提示
+	data, err := ioutil.ReadFile(filepath.Join(folder, "server.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(data)
+	info := &pkcs8EncryptedPrivateKeyInfo{}
+	_, err = asn1.Unmarshal(block.Bytes, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info.EncryptedData = info.EncryptedData[:len(info.EncryptedData)-1]
+	block.Bytes, err = asn1.Marshal(*info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prompts = 0
+	_, err = loader.FromData(pem.EncodeToMemory(block))
+	if err == nil || strings.Contains(err.Error(), errPasswordIncorrect.Error()) || prompts != 1 {
+		t.Errorf("corrupt key should fail without retry: prompts=%d, err=%v", prompts, err)
+	}
+}
+
+// 将DER转换为BER：结构类型使用不定长编码，较长的OCTET STRING分段
+func testToBer(nodes []*encoding.Asn1Node) []byte {
+	ber := make([]byte, 0)
+	for _, node := range nodes {
+		tag := byte(node.Class<<6) | byte(node.Tag)
+		if node.Constructed {
+			ber = append(ber, tag|0x20, 0x80)
+			ber = append(ber, testToBer(node.Children)...)
+			ber = append(ber, 0, 0)
+		} else if node.Class == encoding.Asn1ClassUniversal && node.Tag == asn1.TagOctetString && len(node.Bytes) > 64 {
+			ber = append(ber, tag|0x20, 0x80)
+			for i := 0; i < len(node.Bytes); i += 64 {
+				end := i + 64
+				if end > len(node.Bytes) {
+					end = len(node.Bytes)
+				}
+				chunk, _ := asn1.Marshal(node.Bytes[i:end])
+				ber = append(ber, chunk...)
+			}
+			ber = append(ber, 0, 0)
+		} else {
+			item, _ := asn1.Marshal(asn1.RawValue{Class: node.Class, Tag: node.Tag, Bytes: node.Bytes})
+			ber = append(ber, item...)
+		}
+	}
+
+	return ber
+}
+
+func TestCrt_CreateECDSA(t *testing.T) {
+	folder := testFileFolder()
+	caCrt, caPrivate, err := testCreateCA()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	private := &ECDSAPrivate{Format: "pkcs8"}
+	err = private.Create(elliptic.P256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = private.ToFile(filepath.Join(folder, "ecdsa.key"), "ecdsa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loadedPrivate := &ECDSAPrivate{}
+	err = loadedPrivate.FromFile(filepath.Join(folder, "ecdsa.key"), "ecdsa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, err := loadedPrivate.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !public.key.Equal(private.Public()) {
+		t.Error("public key mismatch")
+	}
+
+	signature, err := private.Sign([]byte("data"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = public.Verify([]byte("data"), signature, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	crtTemplate := &CrtTemplate{
+		Organization:       "client",
+		OrganizationalUnit: "mobile",
+	}
+	template, err := crtTemplate.Template()
+	if err != nil {
+		t.Fatal(err)
+	}
+	crt := &CrtPfx{}
+	err = crt.Create(template, caCrt.certificate, public, caPrivate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crt.ECDSAPublicKey() == nil || crt.Crt.PublicKey() != nil {
+		t.Fatal("certificate public key should be ecdsa")
+	}
+	err = crt.Verify(caCrt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = crt.ToFile(filepath.Join(folder, "ecdsa.pfx"), caCrt, private, "ecdsa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pfx := &CrtPfx{}
+	err = pfx.FromFile(filepath.Join(folder, "ecdsa.pfx"), "ecdsa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pfxPrivate := pfx.ECDSAPrivateKey()
+	if pfxPrivate == nil {
+		t.Fatal("pfx private key should be ecdsa")
+	}
+	if pfxPrivate.Key().D.Cmp(private.Key().D) != 0 {
+		t.Error("pfx private key mismatch")
+	}
+}
+
+func TestCrt_CreateEd25519(t *testing.T) {
+	folder := testFileFolder()
+
+	caPrivate := &Ed25519Private{}
+	err := caPrivate.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	caPublic, err := caPrivate.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate, err := (&CrtTemplate{Organization: "ca", OrganizationalUnit: "mesh"}).Template()
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCrt := &Crt{}
+	err = caCrt.Create(caTemplate, caTemplate, caPublic, caPrivate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	private := &Ed25519Private{}
+	err = private.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = private.ToFile(filepath.Join(folder, "ed25519.key"), "ed25519")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loadedPrivate := &Ed25519Private{}
+	err = loadedPrivate.FromFile(filepath.Join(folder, "ed25519.key"), "ed25519")
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, err := loadedPrivate.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !public.key.Equal(private.Public()) {
+		t.Error("public key mismatch")
+	}
+	signature, err := private.Sign([]byte("data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = public.Verify([]byte("data"), signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template, err := (&CrtTemplate{Organization: "server", OrganizationalUnit: "svc", Hosts: []string{"svc.mesh"}}).Template()
+	if err != nil {
+		t.Fatal(err)
+	}
+	crt := &CrtPfx{}
+	err = crt.Create(template, caCrt.certificate, public, caPrivate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crt.Ed25519PublicKey() == nil {
+		t.Fatal("certificate public key should be ed25519")
+	}
+	err = crt.Verify(caCrt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	crl := &CrtCrl{}
+	err = crl.AddCrt(&crt.Crt, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	crlData, err := crl.ToMemory(caCrt, caPrivate, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = crl.FromMemory(crlData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = crl.Verify(caCrt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pfxData, err := crt.ToMemory(caCrt, private, "ed25519")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pfx := &CrtPfx{}
+	err = pfx.FromMemory(pfxData, "ed25519")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pfxPrivate := pfx.Ed25519PrivateKey()
+	if pfxPrivate == nil {
+		t.Fatal("pfx private key should be ed25519")
+	}
+	if !pfxPrivate.Key().Equal(private.Key()) {
+		t.Error("pfx private key mismatch")
+	}
+}
+
+func TestCrt_CreateWithSigner(t *testing.T) {
+	// 任意crypto.Signer作为签发者私钥
+	caKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caSigner := NewSignerKey(caKey)
+	caTemplate, err := (&CrtTemplate{Organization: "ca", OrganizationalUnit: "signer"}).Template()
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCrt := &Crt{}
+	err = caCrt.Create(caTemplate, caTemplate, caSigner, caSigner)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	private := &RSAPrivate{}
+	err = private.Create(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template, err := (&CrtTemplate{Organization: "server", OrganizationalUnit: "tls", Hosts: []string{"127.0.0.1"}}).Template()
+	if err != nil {
+		t.Fatal(err)
+	}
+	crt := &Crt{}
+	err = crt.Create(template, caCrt.certificate, private, caSigner)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	crl := &CrtCrl{}
+	err = crl.AddCrt(crt, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = crl.ToMemory(caCrt, caSigner, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tlsCrt, err := crt.TlsCertificate(private, caCrt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tlsCrt.PrivateKey.(crypto.Decrypter); !ok {
+		t.Error("tls private key should implement crypto.Decrypter")
+	}
+	if len(tlsCrt.Certificate) != 2 {
+		t.Errorf("expected 2 certificates, got %d", len(tlsCrt.Certificate))
+	}
+}
+
+func TestCrt_CreatePSS(t *testing.T) {
+	private := &RSAPrivate{}
+	err := private.Create(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template, err := (&CrtTemplate{
+		Organization:       "ca",
+		OrganizationalUnit: "pss",
+		SignatureAlgorithm: x509.SHA256WithRSAPSS,
+	}).Template()
+	if err != nil {
+		t.Fatal(err)
+	}
+	crt := &Crt{}
+	err = crt.Create(template, template, private, private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crt.certificate.SignatureAlgorithm != x509.SHA256WithRSAPSS {
+		t.Errorf("signature algorithm expected %v, got %v", x509.SHA256WithRSAPSS, crt.certificate.SignatureAlgorithm)
+	}
+	err = crt.Verify(crt)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCrt_Fingerprint(t *testing.T) {
+	caCrt, caPrivate, err := testCreateCA()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fingerprint, err := caCrt.Fingerprint(&hash.Sha1{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha1.Sum(caCrt.certificate.Raw)
+	if !bytes.Equal(fingerprint, sum[:]) {
+		t.Error("sha1 fingerprint mismatch")
+	}
+	if len(fingerprint.ColonHex()) != 59 || strings.ToUpper(fingerprint.ColonHex()) != fingerprint.ColonHex() {
+		t.Errorf("invalid colon hex: %s", fingerprint.ColonHex())
+	}
+	t.Log("sha1:", fingerprint)
+
+	public, err := caPrivate.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	crtSpki, err := caCrt.SpkiFingerprint(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keySpki, err := public.SpkiFingerprint(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(crtSpki, keySpki) {
+		t.Error("spki fingerprint mismatch")
+	}
+	pin, err := public.PinSha256()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pin != fmt.Sprintf(`pin-sha256="%s"`, keySpki.Base64()) {
+		t.Errorf("invalid pin: %s", pin)
+	}
+
+	// 标准库为CA证书自动生成的SubjectKeyId
+	method1, err := caCrt.SubjectKeyId(SubjectKeyIdMethod1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	methodSha256, err := public.SubjectKeyId(SubjectKeyIdSha256Method)
+	if err != nil {
+		t.Fatal(err)
+	}
+	skid := caCrt.certificate.SubjectKeyId
+	if !bytes.Equal(skid, method1) && !bytes.Equal(skid, methodSha256) {
+		t.Errorf("subject key id mismatch: %x", skid)
+	}
+	method2, err := public.SubjectKeyId(SubjectKeyIdMethod2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(method2) != 8 || method2[0]>>4 != 0x4 || !bytes.Equal(method2[1:], method1[13:]) {
+		t.Errorf("invalid method 2 subject key id: %x", method2)
+	}
+}
+
+func TestCrt_MatchPrivateKey(t *testing.T) {
+	caCrt, caPrivate, err := testCreateCA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverPfx, serverPrivate, err := testCreateServer(caCrt, caPrivate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = caPrivate.MatchCrt(caCrt)
+	if err != nil {
+		t.Error(err)
+	}
+	err = serverPrivate.MatchCrt(caCrt)
+	if err == nil {
+		t.Error("server private key should not match ca certificate")
+	}
+	err = serverPfx.Check()
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = serverPrivate.Validate()
+	if err != nil {
+		t.Error(err)
+	}
+	broken := &RSAPrivate{key: &rsa.PrivateKey{}}
+	*broken.key = *serverPrivate.key
+	broken.key.Precomputed.Dp = new(big.Int).Add(serverPrivate.key.Precomputed.Dp, big.NewInt(1))
+	err = broken.Validate()
+	if err == nil {
+		t.Error("validate should fail with invalid crt parameters")
+	}
+
+	// 按公钥配对目录中的文件
+	folder := filepath.Join(testFileFolder(), "pair")
+	os.RemoveAll(folder)
+	err = caCrt.ToFile(filepath.Join(folder, "ca.crt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = caPrivate.ToFile(filepath.Join(folder, "ca.key"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = serverPfx.Crt.ToFile(filepath.Join(folder, "server.crt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := &RSAPrivate{}
+	err = other.Create(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = other.ToFile(filepath.Join(folder, "other.key"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(folder, "readme.txt"), []byte("not a key"), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := (&Loader{}).PairFolder(folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Pairs) != 1 || filepath.Base(report.Pairs[0].CrtPath) != "ca.crt" || filepath.Base(report.Pairs[0].KeyPath) != "ca.key" {
+		t.Errorf("ca key pair expected, got %d pairs", len(report.Pairs))
+	}
+	if len(report.UnmatchedCrts) != 1 || filepath.Base(report.UnmatchedCrts[0]) != "server.crt" {
+		t.Errorf("unmatched certificate expected server.crt, got %v", report.UnmatchedCrts)
+	}
+	if len(report.UnmatchedKeys) != 1 || filepath.Base(report.UnmatchedKeys[0]) != "other.key" {
+		t.Errorf("unmatched key expected other.key, got %v", report.UnmatchedKeys)
+	}
+	if len(report.Errors) != 1 || filepath.Base(report.Errors[0].Path) != "readme.txt" {
+		t.Errorf("load error expected readme.txt, got %d errors", len(report.Errors))
+	}
+}
+
+func TestRemoteSigner(t *testing.T) {
+	folder := filepath.Join(testFileFolder(), "signer")
+	os.RemoveAll(folder)
+	caPrivate := &RSAPrivate{}
+	err := caPrivate.Create(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = caPrivate.ToFile(filepath.Join(folder, "ca.key"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecPrivate := &ECDSAPrivate{}
+	err = ecPrivate.Create(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ecPrivate.ToFile(filepath.Join(folder, "ec.key"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(&SignerServer{Folder: folder, Token: "token"})
+	defer server.Close()
+
+	_, err = NewRemoteCryptoSigner(&HttpSigner{Url: server.URL, KeyId: "ca", Token: "wrong"})
+	if err == nil {
+		t.Error("remote signer should fail with wrong token")
+	}
+	// 令牌须使用Bearer前缀
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/keys/ca", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "token")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("raw token without bearer prefix should be rejected, got %s", resp.Status)
+	}
+	_, err = NewRemoteCryptoSigner(&HttpSigner{Url: server.URL, KeyId: "none", Token: "token"})
+	if err == nil {
+		t.Error("remote signer should fail with unknown key id")
+	}
+
+	caSigner, err := NewRemoteCryptoSigner(&HttpSigner{Url: server.URL, KeyId: "ca", Token: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	caKey := NewSignerKey(caSigner)
+
+	// 签发证书及吊销列表
+	for _, algorithm := range []x509.SignatureAlgorithm{x509.SHA256WithRSA, x509.SHA384WithRSAPSS} {
+		template, err := (&CrtTemplate{Organization: "ca", OrganizationalUnit: "remote", SignatureAlgorithm: algorithm}).Template()
+		if err != nil {
+			t.Fatal(err)
+		}
+		caCrt := &Crt{}
+		err = caCrt.Create(template, template, caKey, caKey)
+		if err != nil {
+			t.Fatal(algorithm, err)
+		}
+		err = caCrt.Verify(caCrt)
+		if err != nil {
+			t.Error(algorithm, err)
+		}
+		err = caPrivate.MatchCrt(caCrt)
+		if err != nil {
+			t.Error(algorithm, err)
+		}
+
+		crl := &CrtCrl{}
+		err = crl.AddCrt(caCrt, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = crl.ToMemory(caCrt, caKey, nil, nil)
+		if err != nil {
+			t.Fatal(algorithm, err)
+		}
+	}
+
+	// 数据签名
+	data := []byte("remote signer")
+	signature, err := caKey.Sign(data, &hash.Sha256{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	caPublic, err := caPrivate.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = caPublic.Verify(data, signature, &hash.Sha256{})
+	if err != nil {
+		t.Error(err)
+	}
+
+	_, err = caKey.Sign(data, nil)
+	if err == nil {
+		t.Error("sign should fail without hash")
+	}
+
+	ecSigner, err := NewRemoteCryptoSigner(&HttpSigner{Url: server.URL, KeyId: "ec", Token: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, err = NewSignerKey(ecSigner).Sign(data, &hash.Sha256{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecPublic, err := ecPrivate.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ecPublic.Verify(data, signature, nil)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCrtCsr_Create(t *testing.T) {
+	caCrt, caPrivate, err := testCreateCA()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	private := &RSAPrivate{}
+	err = private.Create(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	crtTemplate := &CrtTemplate{
+		Organization:       "server",
+		OrganizationalUnit: "csr",
+		Hosts:              []string{"127.0.0.1", "localhost"},
+		Extensions: []pkix.Extension{
+			{Id: []int{1, 2, 3, 4}, Value: []byte{0x05, 0x00}},
+		},
+		ChallengePassword: "challenge",
+	}
+	csr := &CrtCsr{}
+	err = csr.Create(crtTemplate, private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = csr.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if csr.ChallengePassword() != "challenge" {
+		t.Errorf("challenge password expected %s, got %s", "challenge", csr.ChallengePassword())
+	}
+	// 可打印字符的质询密码使用PrintableString
+	if !bytes.Contains(csr.Request().RawTBSCertificateRequest, append([]byte{asn1.TagPrintableString, 9}, "challenge"...)) {
+		t.Error("challenge password should be encoded as PrintableString")
+	}
+
+	// 仅签名一次，不含质询密码时与标准库生成的请求内容一致
+	signer := &testCountingSigner{Signer: private.key}
+	crtTemplate.ChallengePassword = ""
+	err = csr.Create(crtTemplate, NewSignerKey(signer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer.count != 1 {
+		t.Errorf("expected 1 signature, got %d", signer.count)
+	}
+	expected, err := x509.CreateCertificateRequest(rand.Reader, crtTemplate.Request(), private.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(csr.Request().Raw, expected) {
+		t.Error("certificate request should match standard library output")
+	}
+	crtTemplate.ChallengePassword = "challenge"
+	err = csr.Create(crtTemplate, private)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	folder := testFileFolder()
+	path := filepath.Join(folder, "server.csr")
+	err = csr.ToFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := &CrtCsr{}
+	err = loaded.FromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := csr.ToDer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = loaded.FromData(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := loaded.Request()
+	if loaded.CommonName() != "csr" || len(request.IPAddresses) != 1 || len(request.DNSNames) != 1 || len(request.Extensions) != 2 {
+		t.Error("certificate request content mismatch")
+	}
+	if loaded.PublicKey() == nil || loaded.PublicKey().key.N.Cmp(private.key.N) != 0 {
+		t.Error("certificate request public key mismatch")
+	}
+
+	// 篡改后签名验证失败
+	tampered := append([]byte{}, der...)
+	index := bytes.Index(tampered, []byte("challenge"))
+	tampered[index] = 'C'
+	err = loaded.FromData(tampered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = loaded.Verify()
+	if err == nil {
+		t.Error("verify should fail with tampered request")
+	}
+
+	// 其它签名算法及私钥类型
+	ecPrivate := &ECDSAPrivate{}
+	err = ecPrivate.Create(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range []struct {
+		key       PrivateKey
+		algorithm x509.SignatureAlgorithm
+	}{
+		{private, x509.SHA384WithRSAPSS},
+		{ecPrivate, x509.UnknownSignatureAlgorithm},
+	} {
+		err = csr.Create(&CrtTemplate{OrganizationalUnit: "csr", ChallengePassword: "密码", SignatureAlgorithm: item.algorithm}, item.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = csr.Verify()
+		if err != nil {
+			t.Error(item.algorithm, err)
+		}
+		if csr.ChallengePassword() != "密码" {
+			t.Error(item.algorithm, "challenge password mismatch")
+		}
+		if !bytes.Contains(csr.Request().RawTBSCertificateRequest, append([]byte{asn1.TagUTF8String, 6}, "密码"...)) {
+			t.Error(item.algorithm, "challenge password should be encoded as UTF8String")
+		}
+	}
+
+	// CA使用请求中的公钥签发证书
+	template, err := crtTemplate.Template()
+	if err != nil {
+		t.Fatal(err)
+	}
+	crt := &Crt{}
+	err = crt.Create(template, caCrt.certificate, csr, caPrivate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = crt.MatchPrivateKey(ecPrivate)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+type testCountingSigner struct {
+	crypto.Signer
+	count int
+}
+
+func (s *testCountingSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	s.count++
+
+	return s.Signer.Sign(rand, digest, opts)
+}
+
 func TestCrt_End(t *testing.T) {
 	folder := testFileFolder()
 	os.RemoveAll(folder)
@@ -57,7 +1030,7 @@ func testCreateCA() (*Crt, *RSAPrivate, error) {
 	if err != nil {
 		return nil, nil, err
 	}
-	public, err := private.Public()
+	public, err := private.PublicKey()
 	if err != nil {
 		return nil, nil, err
 	}
@@ -100,7 +1073,7 @@ func testCreateServer(caCrt *Crt, caPrivate *RSAPrivate) (*CrtPfx, *RSAPrivate,
 	if err != nil {
 		return nil, nil, err
 	}
-	public, err := private.Public()
+	public, err := private.PublicKey()
 	if err != nil {
 		return nil, nil, err
 	}
@@ -157,7 +1130,7 @@ func testCreateClient(caCrt *Crt, caPrivate *RSAPrivate) (*CrtPfx, *RSAPrivate,
 	if err != nil {
 		return nil, nil, err
 	}
-	public, err := private.Public()
+	public, err := private.PublicKey()
 	if err != nil {
 		return nil, nil, err
 	}
@@ -0,0 +1,193 @@
+package certificate
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"github.com/csby/security/hash"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type ECDSAPrivate struct {
+	key *ecdsa.PrivateKey
+
+	Format     string // sec1(default) or pkcs8
+	Iterations int    // 使用密码保存时PBKDF2迭代次数，默认DefaultPkcs8Iterations
+}
+
+func (s *ECDSAPrivate) Key() *ecdsa.PrivateKey {
+	return s.key
+}
+
+func (s *ECDSAPrivate) Length() int {
+	if s.key == nil {
+		return 0
+	}
+
+	return s.key.Curve.Params().BitSize
+}
+
+// 获取公钥，Public方法返回标准库公钥
+func (s *ECDSAPrivate) PublicKey() (*ECDSAPublic, error) {
+	if s.key == nil {
+		return nil, fmt.Errorf("invalid private key")
+	}
+
+	key := s.key.PublicKey
+	return &ECDSAPublic{
+		key: &key,
+	}, nil
+}
+
+// 标准库公钥，与crypto.Signer接口的Public方法一致
+func (s *ECDSAPrivate) Public() crypto.PublicKey {
+	if s == nil || s.key == nil {
+		return nil
+	}
+
+	return s.key.Public()
+}
+
+func (s *ECDSAPrivate) CryptoSigner() (crypto.Signer, error) {
+	if s == nil || s.key == nil {
+		return nil, fmt.Errorf("invalid private key")
+	}
+
+	return s.key, nil
+}
+
+func (s *ECDSAPrivate) CryptoPublicKey() (crypto.PublicKey, error) {
+	if s == nil || s.key == nil {
+		return nil, fmt.Errorf("invalid private key")
+	}
+
+	return s.key.Public(), nil
+}
+
+// 签名数据，返回ASN.1 DER编码的签名，h默认为SHA256
+func (s *ECDSAPrivate) Sign(data []byte, h hash.Hash) ([]byte, error) {
+	if s.key == nil {
+		return nil, fmt.Errorf("invalid key")
+	}
+	if h == nil {
+		h = &hash.Sha256{}
+	}
+	hashed, err := h.Hash(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return ecdsa.SignASN1(rand.Reader, s.key, hashed)
+}
+
+// 生成密钥，curve默认为P-256
+func (s *ECDSAPrivate) Create(curve elliptic.Curve) error {
+	if curve == nil {
+		curve = elliptic.P256()
+	}
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return err
+	}
+	s.key = key
+
+	return nil
+}
+
+func (s *ECDSAPrivate) ToMemory(password string) ([]byte, error) {
+	block, err := s.encode(password)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(block), nil
+}
+
+func (s *ECDSAPrivate) ToFile(path, password string) error {
+	block, err := s.encode(password)
+	if err != nil {
+		return err
+	}
+
+	folder := filepath.Dir(path)
+	err = os.MkdirAll(folder, 0777)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return pem.Encode(file, block)
+}
+
+func (s *ECDSAPrivate) FromFile(path, password string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return s.FromData(data, password)
+}
+
+func (s *ECDSAPrivate) FromData(data []byte, password string) error {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return fmt.Errorf("invalid private key file")
+	}
+
+	key, err := decodePrivateKeyBlock(block, password)
+	if err != nil {
+		return err
+	}
+	ecKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return fmt.Errorf("not a ecdsa private key: %T", key)
+	}
+	s.key = ecKey
+
+	return nil
+}
+
+// 使用密码时输出ENCRYPTED PRIVATE KEY(PKCS#8, PBES2)
+func (s *ECDSAPrivate) encode(password string) (block *pem.Block, err error) {
+	if s.key == nil {
+		return nil, fmt.Errorf("invalid key")
+	}
+
+	if len(password) > 0 {
+		data, err := x509.MarshalPKCS8PrivateKey(s.key)
+		if err != nil {
+			return nil, err
+		}
+		return encryptPkcs8Block(data, password, s.Iterations)
+	}
+
+	blockType := "EC PRIVATE KEY"
+	var data []byte
+	if strings.ToLower(s.Format) == "pkcs8" {
+		blockType = "PRIVATE KEY"
+		data, err = x509.MarshalPKCS8PrivateKey(s.key)
+	} else {
+		data, err = x509.MarshalECPrivateKey(s.key)
+	}
+	if err != nil {
+		return
+	}
+
+	block = &pem.Block{
+		Type:  blockType,
+		Bytes: data,
+	}
+	return
+}
@@ -0,0 +1,127 @@
+package certificate
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"github.com/csby/security/encoding"
+	"github.com/csby/security/hash"
+	"io/ioutil"
+)
+
+type ECDSAPublic struct {
+	key *ecdsa.PublicKey
+}
+
+func (s *ECDSAPublic) Key() *ecdsa.PublicKey {
+	return s.key
+}
+
+func (s *ECDSAPublic) Length() int {
+	if s.key == nil {
+		return 0
+	}
+
+	return s.key.Curve.Params().BitSize
+}
+
+func (s *ECDSAPublic) CryptoPublicKey() (crypto.PublicKey, error) {
+	if s == nil || s.key == nil {
+		return nil, fmt.Errorf("invalid public key")
+	}
+
+	return s.key, nil
+}
+
+func (s *ECDSAPublic) Base64() string {
+	data, err := x509.MarshalPKIXPublicKey(s.key)
+	if err != nil {
+		return ""
+	}
+
+	return encoding.ToBase64String(data)
+}
+
+// 公钥(PKIX, DER)使用指定的编码转换为字符串
+func (s *ECDSAPublic) EncodeToString(encoder encoding.Encoder) (string, error) {
+	if s.key == nil {
+		return "", fmt.Errorf("invalid key")
+	}
+	if encoder == nil {
+		return "", fmt.Errorf("invalid encoder: nil")
+	}
+	data, err := x509.MarshalPKIXPublicKey(s.key)
+	if err != nil {
+		return "", err
+	}
+
+	return encoder.EncodeToString(data), nil
+}
+
+// 验证ASN.1 DER编码的签名，h默认为SHA256
+func (s *ECDSAPublic) Verify(data []byte, signature []byte, h hash.Hash) error {
+	if s.key == nil {
+		return fmt.Errorf("invalid key")
+	}
+	if h == nil {
+		h = &hash.Sha256{}
+	}
+	hashed, err := h.Hash(data)
+	if err != nil {
+		return err
+	}
+
+	if !ecdsa.VerifyASN1(s.key, hashed, signature) {
+		return fmt.Errorf("ecdsa: verification error")
+	}
+
+	return nil
+}
+
+func (s *ECDSAPublic) FromFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return s.FromData(data)
+}
+
+func (s *ECDSAPublic) FromData(data []byte) error {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return fmt.Errorf("invalid file")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return err
+	}
+	publicKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("invalid file")
+	}
+	s.key = publicKey
+
+	return nil
+}
+
+func (s *ECDSAPublic) ToMemory() ([]byte, error) {
+	if s.key == nil {
+		return nil, fmt.Errorf("invalid key")
+	}
+
+	data, err := x509.MarshalPKIXPublicKey(s.key)
+	if err != nil {
+		return nil, err
+	}
+
+	block := &pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: data,
+	}
+
+	return pem.EncodeToMemory(block), nil
+}
@@ -0,0 +1,158 @@
+package certificate
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+type Ed25519Private struct {
+	key ed25519.PrivateKey
+
+	Iterations int // 使用密码保存时PBKDF2迭代次数，默认DefaultPkcs8Iterations
+}
+
+func (s *Ed25519Private) Key() ed25519.PrivateKey {
+	return s.key
+}
+
+// 获取公钥，Public方法返回标准库公钥
+func (s *Ed25519Private) PublicKey() (*Ed25519Public, error) {
+	if s.key == nil {
+		return nil, fmt.Errorf("invalid private key")
+	}
+
+	return &Ed25519Public{
+		key: s.key.Public().(ed25519.PublicKey),
+	}, nil
+}
+
+// 标准库公钥，与crypto.Signer接口的Public方法一致
+func (s *Ed25519Private) Public() crypto.PublicKey {
+	if s == nil || s.key == nil {
+		return nil
+	}
+
+	return s.key.Public()
+}
+
+func (s *Ed25519Private) CryptoSigner() (crypto.Signer, error) {
+	if s == nil || s.key == nil {
+		return nil, fmt.Errorf("invalid private key")
+	}
+
+	return s.key, nil
+}
+
+func (s *Ed25519Private) CryptoPublicKey() (crypto.PublicKey, error) {
+	if s == nil || s.key == nil {
+		return nil, fmt.Errorf("invalid private key")
+	}
+
+	return s.key.Public(), nil
+}
+
+// 签名数据，Ed25519算法内部使用SHA-512，无需预先计算摘要
+func (s *Ed25519Private) Sign(data []byte) ([]byte, error) {
+	if s.key == nil {
+		return nil, fmt.Errorf("invalid key")
+	}
+
+	return ed25519.Sign(s.key, data), nil
+}
+
+func (s *Ed25519Private) Create() error {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	s.key = key
+
+	return nil
+}
+
+func (s *Ed25519Private) ToMemory(password string) ([]byte, error) {
+	block, err := s.encode(password)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(block), nil
+}
+
+func (s *Ed25519Private) ToFile(path, password string) error {
+	block, err := s.encode(password)
+	if err != nil {
+		return err
+	}
+
+	folder := filepath.Dir(path)
+	err = os.MkdirAll(folder, 0777)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return pem.Encode(file, block)
+}
+
+func (s *Ed25519Private) FromFile(path, password string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return s.FromData(data, password)
+}
+
+func (s *Ed25519Private) FromData(data []byte, password string) error {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return fmt.Errorf("invalid private key file")
+	}
+
+	key, err := decodePrivateKeyBlock(block, password)
+	if err != nil {
+		return err
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return fmt.Errorf("not a ed25519 private key: %T", key)
+	}
+	s.key = edKey
+
+	return nil
+}
+
+// Ed25519私钥仅支持PKCS#8格式
+func (s *Ed25519Private) encode(password string) (block *pem.Block, err error) {
+	if s.key == nil {
+		return nil, fmt.Errorf("invalid key")
+	}
+
+	data, err := x509.MarshalPKCS8PrivateKey(s.key)
+	if err != nil {
+		return
+	}
+
+	if len(password) > 0 {
+		block, err = encryptPkcs8Block(data, password, s.Iterations)
+	} else {
+		block = &pem.Block{
+			Type:  "PRIVATE KEY",
+			Bytes: data,
+		}
+	}
+	return
+}
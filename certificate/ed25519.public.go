@@ -0,0 +1,110 @@
+package certificate
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"github.com/csby/security/encoding"
+	"io/ioutil"
+)
+
+type Ed25519Public struct {
+	key ed25519.PublicKey
+}
+
+func (s *Ed25519Public) Key() ed25519.PublicKey {
+	return s.key
+}
+
+func (s *Ed25519Public) CryptoPublicKey() (crypto.PublicKey, error) {
+	if s == nil || s.key == nil {
+		return nil, fmt.Errorf("invalid public key")
+	}
+
+	return s.key, nil
+}
+
+func (s *Ed25519Public) Base64() string {
+	data, err := x509.MarshalPKIXPublicKey(s.key)
+	if err != nil {
+		return ""
+	}
+
+	return encoding.ToBase64String(data)
+}
+
+// 公钥(PKIX, DER)使用指定的编码转换为字符串
+func (s *Ed25519Public) EncodeToString(encoder encoding.Encoder) (string, error) {
+	if s.key == nil {
+		return "", fmt.Errorf("invalid key")
+	}
+	if encoder == nil {
+		return "", fmt.Errorf("invalid encoder: nil")
+	}
+	data, err := x509.MarshalPKIXPublicKey(s.key)
+	if err != nil {
+		return "", err
+	}
+
+	return encoder.EncodeToString(data), nil
+}
+
+func (s *Ed25519Public) Verify(data []byte, signature []byte) error {
+	if s.key == nil {
+		return fmt.Errorf("invalid key")
+	}
+
+	if !ed25519.Verify(s.key, data, signature) {
+		return fmt.Errorf("ed25519: verification error")
+	}
+
+	return nil
+}
+
+func (s *Ed25519Public) FromFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return s.FromData(data)
+}
+
+func (s *Ed25519Public) FromData(data []byte) error {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return fmt.Errorf("invalid file")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return err
+	}
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return fmt.Errorf("invalid file")
+	}
+	s.key = publicKey
+
+	return nil
+}
+
+func (s *Ed25519Public) ToMemory() ([]byte, error) {
+	if s.key == nil {
+		return nil, fmt.Errorf("invalid key")
+	}
+
+	data, err := x509.MarshalPKIXPublicKey(s.key)
+	if err != nil {
+		return nil, err
+	}
+
+	block := &pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: data,
+	}
+
+	return pem.EncodeToMemory(block), nil
+}
@@ -0,0 +1,166 @@
+package certificate
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"github.com/csby/security/encoding"
+	"github.com/csby/security/hash"
+	"strings"
+)
+
+const (
+	SubjectKeyIdMethod1      = 1 // RFC 5280 4.2.1.2 (1)：subjectPublicKey的SHA-1(160位)
+	SubjectKeyIdMethod2      = 2 // RFC 5280 4.2.1.2 (2)：0100 + subjectPublicKey的SHA-1最低60位
+	SubjectKeyIdSha256Method = 3 // RFC 7093 2 (1)：subjectPublicKey的SHA-256最左160位
+)
+
+// 指纹(摘要值)
+type Fingerprint []byte
+
+// 小写十六进制，如：3a7f...
+func (s Fingerprint) Hex() string {
+	return hex.EncodeToString(s)
+}
+
+// 冒号分隔的大写十六进制，与openssl x509 -fingerprint输出一致，如：3A:7F:...
+func (s Fingerprint) ColonHex() string {
+	parts := make([]string, len(s))
+	for i, b := range s {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+
+	return strings.Join(parts, ":")
+}
+
+func (s Fingerprint) Base64() string {
+	return encoding.ToBase64String(s)
+}
+
+func (s Fingerprint) String() string {
+	return s.ColonHex()
+}
+
+// 证书指纹：证书(DER)的摘要，h默认为SHA256，常用SHA1、SHA256
+func (s *Crt) Fingerprint(h hash.Hash) (Fingerprint, error) {
+	if s.certificate == nil {
+		return nil, fmt.Errorf("invalid certificate")
+	}
+
+	return fingerprint(s.certificate.Raw, h)
+}
+
+// 公钥指纹：证书公钥信息(SubjectPublicKeyInfo, DER)的摘要，h默认为SHA256
+func (s *Crt) SpkiFingerprint(h hash.Hash) (Fingerprint, error) {
+	if s.certificate == nil {
+		return nil, fmt.Errorf("invalid certificate")
+	}
+
+	return fingerprint(s.certificate.RawSubjectPublicKeyInfo, h)
+}
+
+// HPKP格式的公钥固定值，如：pin-sha256="d6qzRu9zOECb90Uez27xWltNsj0e1Md7GkYYkVoZWmM="
+func (s *Crt) PinSha256() (string, error) {
+	if s.certificate == nil {
+		return "", fmt.Errorf("invalid certificate")
+	}
+
+	return pinSha256(s.certificate.RawSubjectPublicKeyInfo), nil
+}
+
+// 按指定方法计算证书公钥的密钥标识(不读取证书中的SubjectKeyId扩展)
+func (s *Crt) SubjectKeyId(method int) ([]byte, error) {
+	if s.certificate == nil {
+		return nil, fmt.Errorf("invalid certificate")
+	}
+
+	return subjectKeyId(s.certificate.RawSubjectPublicKeyInfo, method)
+}
+
+// 公钥指纹：公钥信息(SubjectPublicKeyInfo, DER)的摘要，h默认为SHA256
+func (s *RSAPublic) SpkiFingerprint(h hash.Hash) (Fingerprint, error) {
+	spki, err := s.spki()
+	if err != nil {
+		return nil, err
+	}
+
+	return fingerprint(spki, h)
+}
+
+// HPKP格式的公钥固定值，如：pin-sha256="d6qzRu9zOECb90Uez27xWltNsj0e1Md7GkYYkVoZWmM="
+func (s *RSAPublic) PinSha256() (string, error) {
+	spki, err := s.spki()
+	if err != nil {
+		return "", err
+	}
+
+	return pinSha256(spki), nil
+}
+
+// 按指定方法计算密钥标识，可用作证书的SubjectKeyId及AuthorityKeyId
+func (s *RSAPublic) SubjectKeyId(method int) ([]byte, error) {
+	spki, err := s.spki()
+	if err != nil {
+		return nil, err
+	}
+
+	return subjectKeyId(spki, method)
+}
+
+func (s *RSAPublic) spki() ([]byte, error) {
+	if s.key == nil {
+		return nil, fmt.Errorf("invalid key")
+	}
+
+	return x509.MarshalPKIXPublicKey(s.key)
+}
+
+func fingerprint(data []byte, h hash.Hash) (Fingerprint, error) {
+	if h == nil {
+		h = &hash.Sha256{}
+	}
+	sum, err := h.Hash(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return sum, nil
+}
+
+func pinSha256(spki []byte) string {
+	sum := sha256.Sum256(spki)
+
+	return fmt.Sprintf(`pin-sha256="%s"`, encoding.ToBase64String(sum[:]))
+}
+
+func subjectKeyId(spki []byte, method int) ([]byte, error) {
+	info := &struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}{}
+	_, err := asn1.Unmarshal(spki, info)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key info: %v", err)
+	}
+	publicKey := info.PublicKey.Bytes
+
+	switch method {
+	case SubjectKeyIdMethod1:
+		sum := sha1.Sum(publicKey)
+		return sum[:], nil
+	case SubjectKeyIdMethod2:
+		sum := sha1.Sum(publicKey)
+		id := sum[len(sum)-8:]
+		id[0] = 0x40 | id[0]&0x0f
+		return id, nil
+	case SubjectKeyIdSha256Method:
+		sum := sha256.Sum256(publicKey)
+		return sum[:20], nil
+	}
+
+	return nil, fmt.Errorf("invalid subject key id method: %d", method)
+}
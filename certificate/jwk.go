@@ -0,0 +1,418 @@
+package certificate
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"github.com/csby/security/encoding"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	httpTimeout     = 30 * time.Second // 默认HTTP请求超时时间
+	httpMaxBodySize = 1 << 20          // HTTP响应内容的最大长度
+)
+
+var defaultHttpClient = &http.Client{Timeout: httpTimeout}
+
+// 读取HTTP响应内容，超过httpMaxBodySize时返回错误
+func readHttpBody(r io.Reader) ([]byte, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(r, httpMaxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > httpMaxBodySize {
+		return nil, fmt.Errorf("response body too large, max %d bytes", httpMaxBodySize)
+	}
+
+	return data, nil
+}
+
+// JSON Web Key，参考：RFC 7517、RFC 7518 6.3
+type Jwk struct {
+	Kty     string   `json:"kty" note:"密钥类型"`
+	Use     string   `json:"use,omitempty" note:"用途：sig或enc"`
+	KeyOps  []string `json:"key_ops,omitempty" note:"允许的操作"`
+	Alg     string   `json:"alg,omitempty" note:"算法，如：RS256"`
+	Kid     string   `json:"kid,omitempty" note:"密钥标识"`
+	X5c     []string `json:"x5c,omitempty" note:"证书链(base64 DER)"`
+	X5t     string   `json:"x5t,omitempty" note:"证书SHA-1指纹"`
+	X5tS256 string   `json:"x5t#S256,omitempty" note:"证书SHA-256指纹"`
+
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	D  string `json:"d,omitempty"`
+	P  string `json:"p,omitempty"`
+	Q  string `json:"q,omitempty"`
+	Dp string `json:"dp,omitempty"`
+	Dq string `json:"dq,omitempty"`
+	Qi string `json:"qi,omitempty"`
+}
+
+func (s *Jwk) IsPrivate() bool {
+	return len(s.D) > 0
+}
+
+// 返回不包含私钥参数的副本
+func (s *Jwk) Public() *Jwk {
+	jwk := *s
+	jwk.D, jwk.P, jwk.Q, jwk.Dp, jwk.Dq, jwk.Qi = "", "", "", "", "", ""
+
+	return &jwk
+}
+
+func (s *Jwk) RSAPublic() (*RSAPublic, error) {
+	key := &RSAPublic{}
+	err := key.FromJwk(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (s *Jwk) RSAPrivate() (*RSAPrivate, error) {
+	key := &RSAPrivate{}
+	err := key.FromJwk(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// JWK Set，参考：RFC 7517 5
+type Jwks struct {
+	Keys []*Jwk `json:"keys"`
+}
+
+func (s *Jwks) Add(jwk *Jwk) {
+	if jwk == nil {
+		return
+	}
+	s.Keys = append(s.Keys, jwk)
+}
+
+func (s *Jwks) Find(kid string) *Jwk {
+	for _, key := range s.Keys {
+		if key.Kid == kid {
+			return key
+		}
+	}
+
+	return nil
+}
+
+// 返回仅包含公钥参数的密钥集，用于对外发布
+func (s *Jwks) Public() *Jwks {
+	jwks := &Jwks{Keys: make([]*Jwk, 0, len(s.Keys))}
+	for _, key := range s.Keys {
+		jwks.Keys = append(jwks.Keys, key.Public())
+	}
+
+	return jwks
+}
+
+func (s *Jwks) ToMemory() ([]byte, error) {
+	if s.Keys == nil {
+		return json.Marshal(&Jwks{Keys: make([]*Jwk, 0)})
+	}
+
+	return json.Marshal(s)
+}
+
+func (s *Jwks) ToFile(path string) error {
+	data, err := s.ToMemory()
+	if err != nil {
+		return err
+	}
+
+	folder := filepath.Dir(path)
+	err = os.MkdirAll(folder, 0777)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0666)
+}
+
+func (s *Jwks) FromMemory(data []byte) error {
+	jwks := &Jwks{}
+	err := json.Unmarshal(data, jwks)
+	if err != nil {
+		return fmt.Errorf("invalid jwks: %v", err)
+	}
+	s.Keys = jwks.Keys
+
+	return nil
+}
+
+func (s *Jwks) FromFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return s.FromMemory(data)
+}
+
+// 从URL获取密钥集，请求超时时间为30秒
+func (s *Jwks) FromUrl(url string) error {
+	return s.FromUrlWithClient(url, nil)
+}
+
+// 使用指定的HTTP客户端从URL获取密钥集，client为空时使用默认客户端(超时时间30秒)，响应内容最大1MB
+func (s *Jwks) FromUrlWithClient(url string, client *http.Client) error {
+	if client == nil {
+		client = defaultHttpClient
+	}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("get jwks fail: %s", resp.Status)
+	}
+
+	data, err := readHttpBody(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	return s.FromMemory(data)
+}
+
+// 以HTTP接口发布密钥集(仅公钥参数)
+func (s *Jwks) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	data, err := s.Public().ToMemory()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/jwk-set+json")
+	w.Write(data)
+}
+
+// JWK指纹，参考：RFC 7638
+func (s *RSAPublic) Thumbprint() (string, error) {
+	if s.key == nil {
+		return "", fmt.Errorf("invalid key")
+	}
+
+	// 成员按字典序排列，且不包含空白字符
+	data := fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`,
+		jwkEncodeInt(big.NewInt(int64(s.key.E))), jwkEncodeInt(s.key.N))
+	sum := sha256.Sum256([]byte(data))
+
+	return encoding.ToBase64RawUrlString(sum[:]), nil
+}
+
+// 转换为JWK，kid为RFC 7638指纹
+func (s *RSAPublic) ToJwk() (*Jwk, error) {
+	if s.key == nil {
+		return nil, fmt.Errorf("invalid key")
+	}
+	kid, err := s.Thumbprint()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Jwk{
+		Kty: "RSA",
+		Kid: kid,
+		N:   jwkEncodeInt(s.key.N),
+		E:   jwkEncodeInt(big.NewInt(int64(s.key.E))),
+	}, nil
+}
+
+func (s *RSAPublic) FromJwk(jwk *Jwk) error {
+	if jwk == nil {
+		return fmt.Errorf("invalid jwk: nil")
+	}
+	if jwk.Kty != "RSA" {
+		return fmt.Errorf("not support key type: %s", jwk.Kty)
+	}
+	n, err := jwkDecodeInt("n", jwk.N)
+	if err != nil {
+		return err
+	}
+	e, err := jwkDecodeInt("e", jwk.E)
+	if err != nil {
+		return err
+	}
+	if !e.IsInt64() || e.Int64() > 1<<31-1 || e.Int64() < 3 {
+		return fmt.Errorf("invalid jwk parameter 'e'")
+	}
+	s.key = &rsa.PublicKey{N: n, E: int(e.Int64())}
+
+	return nil
+}
+
+// 转换为包含私钥参数的JWK，kid为公钥的RFC 7638指纹
+func (s *RSAPrivate) ToJwk() (*Jwk, error) {
+	if s.key == nil {
+		return nil, fmt.Errorf("invalid key")
+	}
+	if len(s.key.Primes) != 2 {
+		return nil, fmt.Errorf("not support multi-prime key")
+	}
+	jwk, err := (&RSAPublic{key: &s.key.PublicKey}).ToJwk()
+	if err != nil {
+		return nil, err
+	}
+
+	s.key.Precompute()
+	jwk.D = jwkEncodeInt(s.key.D)
+	jwk.P = jwkEncodeInt(s.key.Primes[0])
+	jwk.Q = jwkEncodeInt(s.key.Primes[1])
+	jwk.Dp = jwkEncodeInt(s.key.Precomputed.Dp)
+	jwk.Dq = jwkEncodeInt(s.key.Precomputed.Dq)
+	jwk.Qi = jwkEncodeInt(s.key.Precomputed.Qinv)
+
+	return jwk, nil
+}
+
+func (s *RSAPrivate) FromJwk(jwk *Jwk) error {
+	public := &RSAPublic{}
+	err := public.FromJwk(jwk)
+	if err != nil {
+		return err
+	}
+	d, err := jwkDecodeInt("d", jwk.D)
+	if err != nil {
+		return err
+	}
+	p, err := jwkDecodeInt("p", jwk.P)
+	if err != nil {
+		return err
+	}
+	q, err := jwkDecodeInt("q", jwk.Q)
+	if err != nil {
+		return err
+	}
+
+	key := &rsa.PrivateKey{
+		PublicKey: *public.key,
+		D:         d,
+		Primes:    []*big.Int{p, q},
+	}
+	err = key.Validate()
+	if err != nil {
+		return fmt.Errorf("invalid jwk private key: %v", err)
+	}
+	key.Precompute()
+	s.key = key
+
+	return nil
+}
+
+// 转换为包含证书信息(x5c、x5t、x5t#S256)的公钥JWK，chain为附加的上级证书
+func (s *Crt) ToJwk(chain ...*Crt) (*Jwk, error) {
+	public := s.PublicKey()
+	if public == nil {
+		return nil, fmt.Errorf("invalid certificate public key")
+	}
+	jwk, err := public.ToJwk()
+	if err != nil {
+		return nil, err
+	}
+
+	jwk.X5c = []string{encoding.ToBase64String(s.certificate.Raw)}
+	for _, c := range chain {
+		if c == nil || c.certificate == nil {
+			return nil, fmt.Errorf("invalid chain certificate")
+		}
+		jwk.X5c = append(jwk.X5c, encoding.ToBase64String(c.certificate.Raw))
+	}
+	sum1 := sha1.Sum(s.certificate.Raw)
+	jwk.X5t = encoding.ToBase64RawUrlString(sum1[:])
+	sum256 := sha256.Sum256(s.certificate.Raw)
+	jwk.X5tS256 = encoding.ToBase64RawUrlString(sum256[:])
+
+	return jwk, nil
+}
+
+// 从JWK的x5c中读取证书(第一个)
+// JWK包含n、e时须与证书公钥一致，包含x5t、x5t#S256时须与证书指纹一致
+func (s *Crt) FromJwk(jwk *Jwk) error {
+	if jwk == nil || len(jwk.X5c) < 1 {
+		return fmt.Errorf("invalid jwk: x5c not found")
+	}
+	data, err := encoding.FromBase64String(jwk.X5c[0])
+	if err != nil {
+		return fmt.Errorf("invalid jwk x5c: %v", err)
+	}
+	certificate, err := x509.ParseCertificate(data)
+	if err != nil {
+		return fmt.Errorf("invalid jwk x5c: %v", err)
+	}
+
+	if len(jwk.N) > 0 || len(jwk.E) > 0 {
+		public := &RSAPublic{}
+		err = public.FromJwk(jwk)
+		if err != nil {
+			return err
+		}
+		if matchPublicKey(certificate.PublicKey, public.key) != nil {
+			return fmt.Errorf("invalid jwk: public key does not match x5c certificate")
+		}
+	}
+	if len(jwk.X5t) > 0 {
+		sum := sha1.Sum(data)
+		err = jwkCheckThumbprint("x5t", jwk.X5t, sum[:])
+		if err != nil {
+			return err
+		}
+	}
+	if len(jwk.X5tS256) > 0 {
+		sum := sha256.Sum256(data)
+		err = jwkCheckThumbprint("x5t#S256", jwk.X5tS256, sum[:])
+		if err != nil {
+			return err
+		}
+	}
+	s.certificate = certificate
+
+	return nil
+}
+
+func jwkCheckThumbprint(name, v string, sum []byte) error {
+	data, err := encoding.FromBase64RawUrlString(v)
+	if err != nil {
+		return fmt.Errorf("invalid jwk parameter '%s': %v", name, err)
+	}
+	if !bytes.Equal(data, sum) {
+		return fmt.Errorf("invalid jwk: %s does not match x5c certificate", name)
+	}
+
+	return nil
+}
+
+func jwkEncodeInt(v *big.Int) string {
+	return encoding.ToBase64RawUrlString(v.Bytes())
+}
+
+func jwkDecodeInt(name, v string) (*big.Int, error) {
+	if len(v) < 1 {
+		return nil, fmt.Errorf("invalid jwk: parameter '%s' not found", name)
+	}
+	data, err := encoding.FromBase64RawUrlString(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jwk parameter '%s': %v", name, err)
+	}
+
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,142 @@
+package certificate
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"github.com/csby/security/hash"
+)
+
+// 私钥接口，用于签发证书、生成吊销列表及PFX文件
+// 由RSAPrivate、ECDSAPrivate、Ed25519Private实现，其它crypto.Signer可通过NewSignerKey包装
+type PrivateKey interface {
+	CryptoSigner() (crypto.Signer, error)
+}
+
+// 公钥接口，用于签发证书
+// 由RSAPublic、ECDSAPublic、Ed25519Public实现，其它crypto.PublicKey可通过NewPublicKey包装
+type PublicKey interface {
+	CryptoPublicKey() (crypto.PublicKey, error)
+}
+
+// 包装任意crypto.Signer(如：硬件密钥、tls.Certificate.PrivateKey)
+type SignerKey struct {
+	signer crypto.Signer
+}
+
+func NewSignerKey(signer crypto.Signer) *SignerKey {
+	return &SignerKey{signer: signer}
+}
+
+func (s *SignerKey) CryptoSigner() (crypto.Signer, error) {
+	if s == nil || s.signer == nil {
+		return nil, fmt.Errorf("invalid private key")
+	}
+
+	return s.signer, nil
+}
+
+func (s *SignerKey) CryptoPublicKey() (crypto.PublicKey, error) {
+	if s == nil || s.signer == nil {
+		return nil, fmt.Errorf("invalid private key")
+	}
+
+	return s.signer.Public(), nil
+}
+
+// 对数据签名，h不能为空(Ed25519密钥忽略h)
+// RSA密钥使用PKCS#1 v1.5填充，可使用RSAPublic.Verify验证，ECDSA密钥可使用ECDSAPublic.Verify验证
+func (s *SignerKey) Sign(data []byte, h hash.Hash) ([]byte, error) {
+	if s == nil || s.signer == nil {
+		return nil, fmt.Errorf("invalid private key")
+	}
+	if _, ok := s.signer.Public().(ed25519.PublicKey); ok {
+		return s.signer.Sign(rand.Reader, data, crypto.Hash(0))
+	}
+
+	if h == nil {
+		return nil, fmt.Errorf("invalid hash: nil")
+	}
+	hashed, err := h.Hash(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.signer.Sign(rand.Reader, hashed, h.Type())
+}
+
+// 包装任意crypto.PublicKey
+type PublicKeyWrapper struct {
+	key crypto.PublicKey
+}
+
+func NewPublicKey(key crypto.PublicKey) *PublicKeyWrapper {
+	return &PublicKeyWrapper{key: key}
+}
+
+func (s *PublicKeyWrapper) CryptoPublicKey() (crypto.PublicKey, error) {
+	if s == nil || s.key == nil {
+		return nil, fmt.Errorf("invalid public key")
+	}
+
+	return s.key, nil
+}
+
+// 解析私钥数据块(PKCS#1、PKCS#8、SEC1或OpenSSH)，加密的数据块(PBES2或旧版PEM加密)使用password解密
+func decodePrivateKeyBlock(block *pem.Block, password string) (interface{}, error) {
+	if block.Type == sshPrivateKeyPemType {
+		key, _, err := parseOpenSSHPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
+
+	// 解密后数据无法解析时同样视为密码错误
+	if block.Type == "ENCRYPTED PRIVATE KEY" {
+		data, err := decryptPkcs8(block.Bytes, password)
+		if err != nil {
+			return nil, err
+		}
+		key, err := x509.ParsePKCS8PrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errPasswordInvalid, err)
+		}
+		return key, nil
+	}
+
+	// 兼容旧版OpenSSL加密格式(Proc-Type: 4,ENCRYPTED)
+	if x509.IsEncryptedPEMBlock(block) {
+		blockData, err := x509.DecryptPEMBlock(block, []byte(password))
+		if err == x509.IncorrectPasswordError {
+			return nil, fmt.Errorf("%w: %v", errPasswordInvalid, err)
+		} else if err != nil {
+			return nil, err
+		}
+		key, err := parsePrivateKey(blockData)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errPasswordInvalid, err)
+		}
+		return key, nil
+	}
+
+	return parsePrivateKey(block.Bytes)
+}
+
+func parsePrivateKey(der []byte) (interface{}, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
@@ -0,0 +1,250 @@
+package certificate
+
+import (
+	"crypto"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"sort"
+)
+
+// 校验私钥内部参数：n = p * q，e * d ≡ 1 (mod λ(n))，素因子为素数，CRT参数(dP, dQ, qInv)正确
+func (s *RSAPrivate) Validate() error {
+	if s.key == nil {
+		return fmt.Errorf("invalid key")
+	}
+	key := s.key
+	err := key.Validate()
+	if err != nil {
+		return err
+	}
+
+	one := big.NewInt(1)
+	for i, prime := range key.Primes {
+		if !prime.ProbablyPrime(20) {
+			return fmt.Errorf("rsa: prime %d is not prime", i+1)
+		}
+	}
+	if len(key.Primes) < 2 {
+		return fmt.Errorf("rsa: invalid number of primes: %d", len(key.Primes))
+	}
+
+	p, q := key.Primes[0], key.Primes[1]
+	precomputed := key.Precomputed
+	if precomputed.Dp != nil {
+		dp := new(big.Int).Mod(key.D, new(big.Int).Sub(p, one))
+		if dp.Cmp(precomputed.Dp) != 0 {
+			return fmt.Errorf("rsa: invalid crt exponent dP")
+		}
+	}
+	if precomputed.Dq != nil {
+		dq := new(big.Int).Mod(key.D, new(big.Int).Sub(q, one))
+		if dq.Cmp(precomputed.Dq) != 0 {
+			return fmt.Errorf("rsa: invalid crt exponent dQ")
+		}
+	}
+	if precomputed.Qinv != nil {
+		check := new(big.Int).Mul(precomputed.Qinv, q)
+		if check.Mod(check, p).Cmp(one) != 0 {
+			return fmt.Errorf("rsa: invalid crt coefficient qInv")
+		}
+	}
+
+	return nil
+}
+
+// 检查私钥是否与证书公钥匹配
+func (s *RSAPrivate) MatchCrt(crt *Crt) error {
+	if crt == nil {
+		return fmt.Errorf("invalid certificate: nil")
+	}
+
+	return crt.MatchPrivateKey(s)
+}
+
+// 检查证书公钥是否与私钥匹配，privateKey可为任意类型的私钥
+func (s *Crt) MatchPrivateKey(privateKey PrivateKey) error {
+	if s.certificate == nil {
+		return fmt.Errorf("invalid certificate")
+	}
+	if privateKey == nil {
+		return fmt.Errorf("invalid private key: nil")
+	}
+	signer, err := privateKey.CryptoSigner()
+	if err != nil {
+		return err
+	}
+
+	return matchPublicKey(s.certificate.PublicKey, signer.Public())
+}
+
+// 检查PFX中的私钥与证书是否匹配，RSA私钥同时校验内部参数
+func (s *CrtPfx) Check() error {
+	if s.certificate == nil {
+		return fmt.Errorf("invalid certificate")
+	}
+	if s.tlsCertificate == nil || s.tlsCertificate.PrivateKey == nil {
+		return fmt.Errorf("invalid private key")
+	}
+	if rsaKey := s.PrivateKey(); rsaKey != nil {
+		err := rsaKey.Validate()
+		if err != nil {
+			return err
+		}
+	}
+	signer, ok := s.tlsCertificate.PrivateKey.(crypto.Signer)
+	if !ok {
+		return fmt.Errorf("not support private key: %T", s.tlsCertificate.PrivateKey)
+	}
+
+	return matchPublicKey(s.certificate.PublicKey, signer.Public())
+}
+
+func matchPublicKey(expected, actual crypto.PublicKey) error {
+	key, ok := expected.(interface {
+		Equal(x crypto.PublicKey) bool
+	})
+	if !ok {
+		return fmt.Errorf("not support public key: %T", expected)
+	}
+	if !key.Equal(actual) {
+		return fmt.Errorf("private key does not match certificate")
+	}
+
+	return nil
+}
+
+type KeyPair struct {
+	CrtPath    string `json:"crtPath" note:"证书文件路径"`
+	KeyPath    string `json:"keyPath" note:"私钥文件路径"`
+	CommonName string `json:"commonName" note:"证书显示名称"`
+	Error      string `json:"error,omitempty" note:"私钥内部参数校验失败的原因"`
+}
+
+type KeyPairError struct {
+	Path  string `json:"path" note:"文件路径"`
+	Error string `json:"error" note:"加载失败的原因"`
+}
+
+type KeyPairReport struct {
+	Pairs         []*KeyPair      `json:"pairs" note:"匹配的证书及私钥"`
+	UnmatchedCrts []string        `json:"unmatchedCrts" note:"没有匹配私钥的证书文件"`
+	UnmatchedKeys []string        `json:"unmatchedKeys" note:"没有匹配证书的私钥文件"`
+	Errors        []*KeyPairError `json:"errors" note:"无法加载的文件"`
+}
+
+type keyPairCrt struct {
+	path    string
+	crt     *Crt
+	matched bool
+}
+
+type keyPairKey struct {
+	path    string
+	signer  crypto.Signer
+	err     error
+	matched bool
+}
+
+// 加载目录(不含子目录)中的证书及私钥文件，并按公钥配对，文件格式及密码的处理同FromFile
+func (s *Loader) PairFolder(folder string) (*KeyPairReport, error) {
+	entries, err := ioutil.ReadDir(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &KeyPairReport{
+		Pairs:         make([]*KeyPair, 0),
+		UnmatchedCrts: make([]string, 0),
+		UnmatchedKeys: make([]string, 0),
+		Errors:        make([]*KeyPairError, 0),
+	}
+	crts := make([]*keyPairCrt, 0)
+	keys := make([]*keyPairKey, 0)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(folder, entry.Name())
+		result, err := s.FromFile(path)
+		if err != nil {
+			report.Errors = append(report.Errors, &KeyPairError{Path: path, Error: err.Error()})
+			continue
+		}
+
+		for _, crt := range result.Certificates {
+			crts = append(crts, &keyPairCrt{path: path, crt: crt})
+		}
+		privateKeys := make([]PrivateKey, 0)
+		for _, key := range result.PrivateKeys {
+			privateKeys = append(privateKeys, key)
+		}
+		for _, key := range result.ECDSAPrivateKeys {
+			privateKeys = append(privateKeys, key)
+		}
+		for _, key := range result.Ed25519PrivateKeys {
+			privateKeys = append(privateKeys, key)
+		}
+		for _, privateKey := range privateKeys {
+			item := &keyPairKey{path: path}
+			if rsaKey, ok := privateKey.(*RSAPrivate); ok {
+				item.err = rsaKey.Validate()
+			}
+			item.signer, err = privateKey.CryptoSigner()
+			if err != nil {
+				report.Errors = append(report.Errors, &KeyPairError{Path: path, Error: err.Error()})
+				continue
+			}
+			keys = append(keys, item)
+		}
+	}
+
+	for _, crt := range crts {
+		for _, key := range keys {
+			// 与MatchPrivateKey使用相同的比较方式
+			if matchPublicKey(crt.crt.certificate.PublicKey, key.signer.Public()) != nil {
+				continue
+			}
+			pair := &KeyPair{
+				CrtPath:    crt.path,
+				KeyPath:    key.path,
+				CommonName: crt.crt.CommonName(),
+			}
+			if key.err != nil {
+				pair.Error = key.err.Error()
+			}
+			report.Pairs = append(report.Pairs, pair)
+			crt.matched = true
+			key.matched = true
+		}
+	}
+	for _, crt := range crts {
+		if !crt.matched {
+			report.UnmatchedCrts = appendUnique(report.UnmatchedCrts, crt.path)
+		}
+	}
+	for _, key := range keys {
+		if !key.matched {
+			report.UnmatchedKeys = appendUnique(report.UnmatchedKeys, key.path)
+		}
+	}
+	sort.Slice(report.Pairs, func(i, j int) bool {
+		if report.Pairs[i].CrtPath != report.Pairs[j].CrtPath {
+			return report.Pairs[i].CrtPath < report.Pairs[j].CrtPath
+		}
+		return report.Pairs[i].KeyPath < report.Pairs[j].KeyPath
+	})
+
+	return report, nil
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+
+	return append(values, value)
+}
@@ -0,0 +1,353 @@
+package certificate
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/csby/security/encoding"
+	"github.com/csby/security/pkcs12"
+	"io/ioutil"
+)
+
+const (
+	LoadFormatPem    = "pem"
+	LoadFormatDer    = "der"
+	LoadFormatBase64 = "base64"
+	LoadFormatPkcs12 = "pkcs12"
+)
+
+// 自动识别并加载证书及密钥文件(.pem, .crt, .cer, .der, .key, .p12, .pfx等)
+// 支持PEM(可包含多个数据块)、DER、base64编码的DER及PKCS#12格式
+type Loader struct {
+	// 密码，用于加密的私钥及PKCS#12文件
+	Password string
+
+	// 获取密码，仅在需要密码且Password为空或不正确时调用，参数为需要密码的对象描述
+	PasswordPrompt func(description string) (string, error)
+}
+
+type LoadResult struct {
+	Format             string            `json:"format" note:"文件格式：pem, der, base64, pkcs12"`
+	Certificates       []*Crt            `json:"-" note:"证书"`
+	PrivateKeys        []*RSAPrivate     `json:"-" note:"RSA私钥"`
+	PublicKeys         []*RSAPublic      `json:"-" note:"RSA公钥"`
+	ECDSAPrivateKeys   []*ECDSAPrivate   `json:"-" note:"ECDSA私钥"`
+	ECDSAPublicKeys    []*ECDSAPublic    `json:"-" note:"ECDSA公钥"`
+	Ed25519PrivateKeys []*Ed25519Private `json:"-" note:"Ed25519私钥"`
+	Ed25519PublicKeys  []*Ed25519Public  `json:"-" note:"Ed25519公钥"`
+	Crls               []*CrtCrl         `json:"-" note:"证书吊销列表"`
+	Others             []*pem.Block      `json:"-" note:"未处理的数据块，如证书请求"`
+}
+
+func (s *LoadResult) IsEmpty() bool {
+	return len(s.Certificates) == 0 &&
+		len(s.PrivateKeys) == 0 &&
+		len(s.PublicKeys) == 0 &&
+		len(s.ECDSAPrivateKeys) == 0 &&
+		len(s.ECDSAPublicKeys) == 0 &&
+		len(s.Ed25519PrivateKeys) == 0 &&
+		len(s.Ed25519PublicKeys) == 0 &&
+		len(s.Crls) == 0 &&
+		len(s.Others) == 0
+}
+
+func (s *Loader) FromFile(path string) (*LoadResult, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.FromData(data)
+}
+
+func (s *Loader) FromData(data []byte) (*LoadResult, error) {
+	if bytes.Contains(data, []byte("-----BEGIN ")) {
+		bundle := &PemBundle{}
+		err := bundle.FromData(data)
+		if err != nil {
+			return nil, err
+		}
+		result := &LoadResult{Format: LoadFormatPem}
+		err = s.loadPemBundle(bundle, result)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	format := LoadFormatDer
+	der := data
+	if !s.isAsn1Sequence(der) {
+		decoded, err := encoding.FromBase64LenientString(string(data))
+		if err != nil || !s.isAsn1Sequence(decoded) {
+			return nil, fmt.Errorf("unknown data format")
+		}
+		format = LoadFormatBase64
+		der = decoded
+	}
+
+	if s.isPkcs12(der) {
+		result := &LoadResult{Format: LoadFormatPkcs12}
+		err := s.loadPkcs12(der, result)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	result := &LoadResult{Format: format}
+	err := s.loadDer(der, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *Loader) loadPemBundle(bundle *PemBundle, result *LoadResult) error {
+	for index, item := range bundle.Items {
+		switch item.Kind {
+		case PemCertificate:
+			crt, err := item.Crt()
+			if err != nil {
+				return err
+			}
+			result.Certificates = append(result.Certificates, crt)
+		case PemPrivateKeyPkcs1, PemPrivateKeyPkcs8, PemPrivateKeyEc, PemPrivateKeyOpenSSH:
+			key, err := item.PrivateKey("")
+			if err != nil {
+				return err
+			}
+			err = s.addPrivateKey(result, key, item.Kind == PemPrivateKeyPkcs8)
+			if err != nil {
+				return err
+			}
+		case PemPrivateKeyEncrypted:
+			key, err := s.loadEncryptedKey(item, index)
+			if err != nil {
+				return err
+			}
+			err = s.addPrivateKey(result, key, item.Block.Type == "ENCRYPTED PRIVATE KEY")
+			if err != nil {
+				return err
+			}
+		case PemPublicKey, PemPublicKeyPkcs1:
+			key, err := item.PublicKey()
+			if err != nil {
+				return err
+			}
+			err = s.addPublicKey(result, key)
+			if err != nil {
+				return err
+			}
+		case PemCrl:
+			crl, err := item.CrtCrl()
+			if err != nil {
+				return err
+			}
+			result.Crls = append(result.Crls, crl)
+		default:
+			result.Others = append(result.Others, item.Block)
+		}
+	}
+
+	return nil
+}
+
+func (s *Loader) loadEncryptedKey(item *PemItem, index int) (interface{}, error) {
+	description := fmt.Sprintf("private key (block %d)", index+1)
+	var key interface{}
+	err := s.withPassword(description, func(password string) error {
+		if len(password) < 1 {
+			return errPasswordRequired
+		}
+		k, err := item.PrivateKey(password)
+		if errors.Is(err, errPasswordInvalid) {
+			return errPasswordIncorrect
+		} else if err != nil {
+			return err
+		}
+		key = k
+		return nil
+	})
+
+	return key, err
+}
+
+func (s *Loader) loadPkcs12(data []byte, result *LoadResult) error {
+	var blocks []*pem.Block
+	err := s.withPassword("pkcs12", func(password string) error {
+		b, err := pkcs12.ToPEM(data, password)
+		if err == pkcs12.ErrIncorrectPassword || err == pkcs12.ErrDecryption {
+			return errPasswordIncorrect
+		} else if err != nil {
+			return err
+		}
+		blocks = b
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	bundle := &PemBundle{}
+	for _, block := range blocks {
+		bundle.Add(block)
+	}
+
+	return s.loadPemBundle(bundle, result)
+}
+
+func (s *Loader) loadDer(data []byte, result *LoadResult) error {
+	if crts, err := x509.ParseCertificates(data); err == nil && len(crts) > 0 {
+		for _, c := range crts {
+			result.Certificates = append(result.Certificates, &Crt{certificate: c})
+		}
+		return nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(data); err == nil {
+		return s.addPrivateKey(result, key, false)
+	}
+	if key, err := x509.ParseECPrivateKey(data); err == nil {
+		return s.addPrivateKey(result, key, false)
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(data); err == nil {
+		return s.addPrivateKey(result, key, true)
+	}
+	if key, err := x509.ParsePKIXPublicKey(data); err == nil {
+		return s.addPublicKey(result, key)
+	}
+	if key, err := x509.ParsePKCS1PublicKey(data); err == nil {
+		return s.addPublicKey(result, key)
+	}
+	if _, err := x509.ParseDERCRL(data); err == nil {
+		crl := &CrtCrl{}
+		err = crl.FromMemory(data)
+		if err != nil {
+			return err
+		}
+		result.Crls = append(result.Crls, crl)
+		return nil
+	}
+	if _, err := x509.ParseCertificateRequest(data); err == nil {
+		result.Others = append(result.Others, &pem.Block{Type: "CERTIFICATE REQUEST", Bytes: data})
+		return nil
+	}
+
+	return fmt.Errorf("unknown der content")
+}
+
+func (s *Loader) addPrivateKey(result *LoadResult, key interface{}, pkcs8 bool) error {
+	format := ""
+	if pkcs8 {
+		format = "pkcs8"
+	}
+
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		result.PrivateKeys = append(result.PrivateKeys, &RSAPrivate{key: k, Format: format})
+	case *ecdsa.PrivateKey:
+		result.ECDSAPrivateKeys = append(result.ECDSAPrivateKeys, &ECDSAPrivate{key: k, Format: format})
+	case ed25519.PrivateKey:
+		result.Ed25519PrivateKeys = append(result.Ed25519PrivateKeys, &Ed25519Private{key: k})
+	default:
+		return fmt.Errorf("not support private key type: %T", key)
+	}
+
+	return nil
+}
+
+func (s *Loader) addPublicKey(result *LoadResult, key interface{}) error {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		result.PublicKeys = append(result.PublicKeys, &RSAPublic{key: k})
+	case *ecdsa.PublicKey:
+		result.ECDSAPublicKeys = append(result.ECDSAPublicKeys, &ECDSAPublic{key: k})
+	case ed25519.PublicKey:
+		result.Ed25519PublicKeys = append(result.Ed25519PublicKeys, &Ed25519Public{key: k})
+	default:
+		return fmt.Errorf("not support public key type: %T", key)
+	}
+
+	return nil
+}
+
+// 依次使用Password及PasswordPrompt获取的密码调用fn，直到成功或无法获取新的密码
+func (s *Loader) withPassword(description string, fn func(password string) error) error {
+	err := fn(s.Password)
+	if err != errPasswordRequired && err != errPasswordIncorrect {
+		return err
+	}
+	if s.PasswordPrompt == nil {
+		return fmt.Errorf("%s: %v", description, err)
+	}
+
+	for retry := 0; retry < 3; retry++ {
+		password, promptErr := s.PasswordPrompt(description)
+		if promptErr != nil {
+			return promptErr
+		}
+		err = fn(password)
+		if err != errPasswordRequired && err != errPasswordIncorrect {
+			return err
+		}
+	}
+
+	return fmt.Errorf("%s: %v", description, err)
+}
+
+func (s *Loader) isAsn1Sequence(data []byte) bool {
+	return len(data) > 2 && data[0] == 0x30
+}
+
+// 按BER解析，兼容不定长编码的PFX文件
+func (s *Loader) isPkcs12(data []byte) bool {
+	nodes, err := (&encoding.Asn1Parser{}).Parse(data)
+	if err != nil || len(nodes) < 1 {
+		return false
+	}
+	pfx := nodes[0]
+	if !s.isUniversal(pfx, asn1.TagSequence) || len(pfx.Children) < 2 {
+		return false
+	}
+	version := pfx.Children[0]
+	if !s.isUniversal(version, asn1.TagInteger) || !bytes.Equal(version.Bytes, []byte{3}) {
+		return false
+	}
+	authSafe := pfx.Children[1]
+	if !s.isUniversal(authSafe, asn1.TagSequence) || len(authSafe.Children) < 1 {
+		return false
+	}
+	contentType := authSafe.Children[0]
+	if !s.isUniversal(contentType, asn1.TagOID) {
+		return false
+	}
+
+	// pkcs7-data or pkcs7-signedData
+	for _, oid := range []asn1.ObjectIdentifier{{1, 2, 840, 113549, 1, 7, 1}, {1, 2, 840, 113549, 1, 7, 2}} {
+		der, _ := asn1.Marshal(oid)
+		if bytes.Equal(contentType.Bytes, der[2:]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Loader) isUniversal(node *encoding.Asn1Node, tag int) bool {
+	return node.Class == encoding.Asn1ClassUniversal && node.Tag == tag
+}
+
+var (
+	errPasswordRequired  = fmt.Errorf("password required")
+	errPasswordIncorrect = fmt.Errorf("password incorrect")
+
+	// 私钥解密失败，由decodePrivateKeyBlock返回
+	errPasswordInvalid = fmt.Errorf("password invalid")
+)
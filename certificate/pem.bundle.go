@@ -0,0 +1,368 @@
+package certificate
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const (
+	PemUnknown             = 0
+	PemCertificate         = 1  // CERTIFICATE、TRUSTED CERTIFICATE、X509 CERTIFICATE
+	PemPrivateKeyPkcs1     = 2  // RSA PRIVATE KEY
+	PemPrivateKeyPkcs8     = 3  // PRIVATE KEY
+	PemPrivateKeyEc        = 4  // EC PRIVATE KEY
+	PemPrivateKeyEncrypted = 5  // ENCRYPTED PRIVATE KEY 或 Proc-Type: 4,ENCRYPTED
+	PemPublicKey           = 6  // PUBLIC KEY
+	PemPublicKeyPkcs1      = 7  // RSA PUBLIC KEY
+	PemCsr                 = 8  // CERTIFICATE REQUEST
+	PemCrl                 = 9  // X509 CRL
+	PemParameters          = 10 // EC PARAMETERS
+	PemPrivateKeyOpenSSH   = 11 // OPENSSH PRIVATE KEY
+)
+
+type PemItem struct {
+	Kind  int
+	Block *pem.Block
+}
+
+func (s *PemItem) IsPrivateKey() bool {
+	return s.Kind == PemPrivateKeyPkcs1 ||
+		s.Kind == PemPrivateKeyPkcs8 ||
+		s.Kind == PemPrivateKeyEc ||
+		s.Kind == PemPrivateKeyEncrypted ||
+		s.Kind == PemPrivateKeyOpenSSH
+}
+
+// 读取证书，TRUSTED CERTIFICATE(OpenSSL格式)忽略证书之后附加的信任设置
+func (s *PemItem) Crt() (*Crt, error) {
+	if s.Kind != PemCertificate {
+		return nil, fmt.Errorf("not a certificate: %s", s.Block.Type)
+	}
+	der := s.Block.Bytes
+	if s.Block.Type == "TRUSTED CERTIFICATE" {
+		value := asn1.RawValue{}
+		_, err := asn1.Unmarshal(der, &value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate: %v", err)
+		}
+		der = value.FullBytes
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate: %v", err)
+	}
+
+	return &Crt{certificate: certificate}, nil
+}
+
+func (s *PemItem) RSAPrivate(password string) (*RSAPrivate, error) {
+	if !s.IsPrivateKey() {
+		return nil, fmt.Errorf("not a private key: %s", s.Block.Type)
+	}
+	key := &RSAPrivate{}
+	if s.Kind == PemPrivateKeyPkcs8 {
+		key.Format = "pkcs8"
+	}
+	err := key.FromData(pem.EncodeToMemory(s.Block), password)
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (s *PemItem) ECDSAPrivate(password string) (*ECDSAPrivate, error) {
+	if !s.IsPrivateKey() {
+		return nil, fmt.Errorf("not a private key: %s", s.Block.Type)
+	}
+	key := &ECDSAPrivate{}
+	if s.Kind == PemPrivateKeyPkcs8 {
+		key.Format = "pkcs8"
+	}
+	err := key.FromData(pem.EncodeToMemory(s.Block), password)
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (s *PemItem) ECDSAPublic() (*ECDSAPublic, error) {
+	if s.Kind != PemPublicKey {
+		return nil, fmt.Errorf("not a public key: %s", s.Block.Type)
+	}
+	key := &ECDSAPublic{}
+	err := key.FromData(pem.EncodeToMemory(s.Block))
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// 解析私钥，返回标准库私钥对象(*rsa.PrivateKey、*ecdsa.PrivateKey等)
+func (s *PemItem) PrivateKey(password string) (interface{}, error) {
+	if !s.IsPrivateKey() {
+		return nil, fmt.Errorf("not a private key: %s", s.Block.Type)
+	}
+
+	return decodePrivateKeyBlock(s.Block, password)
+}
+
+// 解析公钥，返回标准库公钥对象(*rsa.PublicKey、*ecdsa.PublicKey等)
+func (s *PemItem) PublicKey() (interface{}, error) {
+	switch s.Kind {
+	case PemPublicKey:
+		return x509.ParsePKIXPublicKey(s.Block.Bytes)
+	case PemPublicKeyPkcs1:
+		return x509.ParsePKCS1PublicKey(s.Block.Bytes)
+	}
+
+	return nil, fmt.Errorf("not a public key: %s", s.Block.Type)
+}
+
+func (s *PemItem) RSAPublic() (*RSAPublic, error) {
+	var key *rsa.PublicKey
+	switch s.Kind {
+	case PemPublicKey:
+		pub, err := x509.ParsePKIXPublicKey(s.Block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		rsaKey, ok := pub.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("not a rsa public key")
+		}
+		key = rsaKey
+	case PemPublicKeyPkcs1:
+		pub, err := x509.ParsePKCS1PublicKey(s.Block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key = pub
+	default:
+		return nil, fmt.Errorf("not a public key: %s", s.Block.Type)
+	}
+
+	return &RSAPublic{key: key}, nil
+}
+
+func (s *PemItem) CrtCrl() (*CrtCrl, error) {
+	if s.Kind != PemCrl {
+		return nil, fmt.Errorf("not a certificate revocation list: %s", s.Block.Type)
+	}
+	crl := &CrtCrl{}
+	err := crl.FromMemory(pem.EncodeToMemory(s.Block))
+	if err != nil {
+		return nil, err
+	}
+
+	return crl, nil
+}
+
+func (s *PemItem) CrtCsr() (*CrtCsr, error) {
+	if s.Kind != PemCsr {
+		return nil, fmt.Errorf("not a certificate request: %s", s.Block.Type)
+	}
+	csr := &CrtCsr{}
+	err := csr.FromData(s.Block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return csr, nil
+}
+
+// PEM文件中的多个数据块，保持原有顺序及头部信息
+type PemBundle struct {
+	Items []*PemItem
+}
+
+func (s *PemBundle) FromFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return s.FromData(data)
+}
+
+func (s *PemBundle) FromData(data []byte) error {
+	items := make([]*PemItem, 0)
+	rest := data
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		items = append(items, &PemItem{
+			Kind:  PemKind(block),
+			Block: block,
+		})
+	}
+	if len(items) < 1 {
+		return fmt.Errorf("invalid pem data: no block found")
+	}
+	s.Items = items
+
+	return nil
+}
+
+func (s *PemBundle) Add(block *pem.Block) {
+	if block == nil {
+		return
+	}
+	s.Items = append(s.Items, &PemItem{
+		Kind:  PemKind(block),
+		Block: block,
+	})
+}
+
+func (s *PemBundle) AddCrt(crt *Crt) error {
+	if crt == nil || crt.certificate == nil {
+		return fmt.Errorf("invalid certificate")
+	}
+	s.Add(&pem.Block{Type: "CERTIFICATE", Bytes: crt.certificate.Raw})
+
+	return nil
+}
+
+func (s *PemBundle) AddRSAPrivate(key *RSAPrivate, password string) error {
+	if key == nil || key.key == nil {
+		return fmt.Errorf("invalid private key")
+	}
+	block, err := key.encode(password)
+	if err != nil {
+		return err
+	}
+	s.Add(block)
+
+	return nil
+}
+
+// 返回指定类型的数据块
+func (s *PemBundle) Filter(kind int) []*PemItem {
+	items := make([]*PemItem, 0)
+	for _, item := range s.Items {
+		if item.Kind == kind {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// 返回所有证书(按文件中的顺序，通常为证书链)
+func (s *PemBundle) Certificates() ([]*Crt, error) {
+	crts := make([]*Crt, 0)
+	for _, item := range s.Filter(PemCertificate) {
+		crt, err := item.Crt()
+		if err != nil {
+			return nil, err
+		}
+		crts = append(crts, crt)
+	}
+
+	return crts, nil
+}
+
+// 返回第一个私钥
+func (s *PemBundle) PrivateKey() *PemItem {
+	for _, item := range s.Items {
+		if item.IsPrivateKey() {
+			return item
+		}
+	}
+
+	return nil
+}
+
+func (s *PemBundle) ToMemory() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	for _, item := range s.Items {
+		err := pem.Encode(buf, item.Block)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (s *PemBundle) ToFile(path string) error {
+	data, err := s.ToMemory()
+	if err != nil {
+		return err
+	}
+
+	folder := filepath.Dir(path)
+	err = os.MkdirAll(folder, 0777)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(data)
+
+	return err
+}
+
+// 根据类型及内容识别数据块
+func PemKind(block *pem.Block) int {
+	if block == nil {
+		return PemUnknown
+	}
+
+	switch block.Type {
+	case "CERTIFICATE", "TRUSTED CERTIFICATE", "X509 CERTIFICATE":
+		return PemCertificate
+	case "ENCRYPTED PRIVATE KEY":
+		return PemPrivateKeyEncrypted
+	case "RSA PRIVATE KEY", "PRIVATE KEY", "EC PRIVATE KEY":
+		if x509.IsEncryptedPEMBlock(block) {
+			return PemPrivateKeyEncrypted
+		}
+		// 按内容识别，兼容类型标记与实际格式不一致的情况
+		if _, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+			return PemPrivateKeyPkcs1
+		}
+		if _, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+			return PemPrivateKeyPkcs8
+		}
+		if _, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+			return PemPrivateKeyEc
+		}
+		if block.Type == "RSA PRIVATE KEY" {
+			return PemPrivateKeyPkcs1
+		} else if block.Type == "EC PRIVATE KEY" {
+			return PemPrivateKeyEc
+		}
+		return PemPrivateKeyPkcs8
+	case "PUBLIC KEY":
+		return PemPublicKey
+	case "RSA PUBLIC KEY":
+		return PemPublicKeyPkcs1
+	case "CERTIFICATE REQUEST", "NEW CERTIFICATE REQUEST":
+		return PemCsr
+	case "X509 CRL":
+		return PemCrl
+	case "EC PARAMETERS":
+		return PemParameters
+	case sshPrivateKeyPemType:
+		return PemPrivateKeyOpenSSH
+	}
+
+	return PemUnknown
+}
@@ -0,0 +1,253 @@
+package certificate
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"github.com/csby/security/hash"
+)
+
+const (
+	// 加密PKCS#8私钥(ENCRYPTED PRIVATE KEY)PBKDF2默认迭代次数
+	DefaultPkcs8Iterations = 600000
+
+	// PBKDF2最大迭代次数，防止文件中过大的迭代次数消耗CPU
+	MaxPkcs8Iterations = 10000000
+)
+
+// 加密PKCS#8私钥，参考：RFC 5208 6、RFC 8018 6.2
+var (
+	oidPbes2          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPbkdf2         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHmacWithSha1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHmacWithSha256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHmacWithSha384 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHmacWithSha512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+	oidAes128Cbc      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAes192Cbc      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAes256Cbc      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidDesEde3Cbc     = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+)
+
+type pkcs8EncryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	Prf            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// 使用PBES2(PBKDF2-HMAC-SHA256、AES-256-CBC)加密PKCS#8私钥，iterations小于1时使用默认值
+func encryptPkcs8Block(der []byte, password string, iterations int) (*pem.Block, error) {
+	if iterations < 1 {
+		iterations = DefaultPkcs8Iterations
+	}
+	if iterations > MaxPkcs8Iterations {
+		return nil, fmt.Errorf("invalid pbkdf2 iterations: %d, max %d", iterations, MaxPkcs8Iterations)
+	}
+
+	salt := make([]byte, 16)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return nil, err
+	}
+	iv := make([]byte, aes.BlockSize)
+	_, err = rand.Read(iv)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := hash.Pbkdf2(&hash.Sha256{}, []byte(password), salt, iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	encrypted := pkcs7Pad(der, block.BlockSize())
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, encrypted)
+
+	kdfParams, err := asn1.Marshal(pbkdf2Params{
+		Salt:           salt,
+		IterationCount: iterations,
+		Prf: pkix.AlgorithmIdentifier{
+			Algorithm:  oidHmacWithSha256,
+			Parameters: asn1.NullRawValue,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	ivParams, err := asn1.Marshal(iv)
+	if err != nil {
+		return nil, err
+	}
+	params, err := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPbkdf2,
+			Parameters: asn1.RawValue{FullBytes: kdfParams},
+		},
+		EncryptionScheme: pkix.AlgorithmIdentifier{
+			Algorithm:  oidAes256Cbc,
+			Parameters: asn1.RawValue{FullBytes: ivParams},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	data, err := asn1.Marshal(pkcs8EncryptedPrivateKeyInfo{
+		Algorithm: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPbes2,
+			Parameters: asn1.RawValue{FullBytes: params},
+		},
+		EncryptedData: encrypted,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pem.Block{
+		Type:  "ENCRYPTED PRIVATE KEY",
+		Bytes: data,
+	}, nil
+}
+
+// 解密PBES2加密的PKCS#8私钥，返回PKCS#8私钥数据
+func decryptPkcs8(data []byte, password string) ([]byte, error) {
+	info := &pkcs8EncryptedPrivateKeyInfo{}
+	rest, err := asn1.Unmarshal(data, info)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted private key: %v", err)
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("invalid encrypted private key: trailing data")
+	}
+	if !info.Algorithm.Algorithm.Equal(oidPbes2) {
+		return nil, fmt.Errorf("not support encryption algorithm: %v", info.Algorithm.Algorithm)
+	}
+
+	params := &pbes2Params{}
+	_, err = asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pbes2 parameters: %v", err)
+	}
+	if !params.KeyDerivationFunc.Algorithm.Equal(oidPbkdf2) {
+		return nil, fmt.Errorf("not support key derivation function: %v", params.KeyDerivationFunc.Algorithm)
+	}
+	kdf := &pbkdf2Params{}
+	_, err = asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, kdf)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pbkdf2 parameters: %v", err)
+	}
+
+	var prf hash.Hash
+	switch {
+	case len(kdf.Prf.Algorithm) == 0, kdf.Prf.Algorithm.Equal(oidHmacWithSha1):
+		prf = &hash.Sha1{}
+	case kdf.Prf.Algorithm.Equal(oidHmacWithSha256):
+		prf = &hash.Sha256{}
+	case kdf.Prf.Algorithm.Equal(oidHmacWithSha384):
+		prf = &hash.Sha384{}
+	case kdf.Prf.Algorithm.Equal(oidHmacWithSha512):
+		prf = &hash.Sha512{}
+	default:
+		return nil, fmt.Errorf("not support pbkdf2 prf: %v", kdf.Prf.Algorithm)
+	}
+
+	scheme := params.EncryptionScheme.Algorithm
+	keyLength := 0
+	switch {
+	case scheme.Equal(oidAes128Cbc):
+		keyLength = 16
+	case scheme.Equal(oidAes192Cbc):
+		keyLength = 24
+	case scheme.Equal(oidAes256Cbc):
+		keyLength = 32
+	case scheme.Equal(oidDesEde3Cbc):
+		keyLength = 24
+	default:
+		return nil, fmt.Errorf("not support encryption scheme: %v", scheme)
+	}
+	if kdf.IterationCount < 1 || kdf.IterationCount > MaxPkcs8Iterations {
+		return nil, fmt.Errorf("invalid pbkdf2 iterations: %d", kdf.IterationCount)
+	}
+	if kdf.KeyLength > 0 && kdf.KeyLength != keyLength {
+		return nil, fmt.Errorf("invalid pbkdf2 key length: %d", kdf.KeyLength)
+	}
+	var iv []byte
+	_, err = asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption parameters: %v", err)
+	}
+
+	key, err := hash.Pbkdf2(prf, []byte(password), kdf.Salt, kdf.IterationCount, keyLength)
+	if err != nil {
+		return nil, err
+	}
+	var block cipher.Block
+	if scheme.Equal(oidDesEde3Cbc) {
+		block, err = des.NewTripleDESCipher(key)
+	} else {
+		block, err = aes.NewCipher(key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != block.BlockSize() {
+		return nil, fmt.Errorf("invalid encryption iv length: %d", len(iv))
+	}
+	encrypted := info.EncryptedData
+	if len(encrypted) == 0 || len(encrypted)%block.BlockSize() != 0 {
+		return nil, fmt.Errorf("invalid encrypted data length: %d", len(encrypted))
+	}
+
+	decrypted := make([]byte, len(encrypted))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(decrypted, encrypted)
+
+	decrypted, err = pkcs7Unpad(decrypted, block.BlockSize())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errPasswordInvalid, err)
+	}
+
+	return decrypted, nil
+}
+
+func pkcs7Pad(data []byte, blockSize int) []byte {
+	padding := blockSize - len(data)%blockSize
+
+	return append(append([]byte{}, data...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+}
+
+func pkcs7Unpad(data []byte, blockSize int) ([]byte, error) {
+	length := len(data)
+	if length == 0 {
+		return nil, fmt.Errorf("invalid padding")
+	}
+	padding := int(data[length-1])
+	if padding < 1 || padding > blockSize || padding > length {
+		return nil, fmt.Errorf("invalid padding")
+	}
+	for _, b := range data[length-padding:] {
+		if int(b) != padding {
+			return nil, fmt.Errorf("invalid padding")
+		}
+	}
+
+	return data[:length-padding], nil
+}
@@ -0,0 +1,265 @@
+package certificate
+
+import (
+	"crypto/rsa"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	RSAAuditInvalid      = "invalid"          // 密钥无效
+	RSAAuditWeakSize     = "weak-size"        // 模长低于策略要求
+	RSAAuditExponent     = "unusual-exponent" // 非常规公钥指数
+	RSAAuditRoca         = "roca"             // 存在ROCA指纹(CVE-2017-15361)
+	RSAAuditFermat       = "fermat"           // 两个素因子过于接近，可通过费马分解
+	RSAAuditSharedFactor = "shared-factor"    // 与同批次其它密钥存在公共素因子
+)
+
+// RSA密钥质量检查
+type RSAAudit struct {
+	// 最小模长(位)，默认2048
+	MinLength int
+
+	// 费马分解尝试次数，默认100，小于0时不检查
+	FermatRounds int
+}
+
+type RSAAuditIssue struct {
+	Code    string `json:"code" note:"问题代码"`
+	Message string `json:"message" note:"问题描述"`
+}
+
+type RSAAuditResult struct {
+	Index  int              `json:"index" note:"批次中的序号"`
+	Length int              `json:"length" note:"模长(位)"`
+	Issues []*RSAAuditIssue `json:"issues" note:"发现的问题"`
+}
+
+func (s *RSAAuditResult) IsWeak() bool {
+	return len(s.Issues) > 0
+}
+
+func (s *RSAAuditResult) HasIssue(code string) bool {
+	for _, issue := range s.Issues {
+		if issue.Code == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *RSAAuditResult) addIssue(code, format string, a ...interface{}) {
+	s.Issues = append(s.Issues, &RSAAuditIssue{
+		Code:    code,
+		Message: fmt.Sprintf(format, a...),
+	})
+}
+
+// 检查单个密钥，key可为RSAPublic、RSAPrivate或其它RSA公钥
+func (s *RSAAudit) Check(key PublicKey) *RSAAuditResult {
+	return s.CheckBatch([]PublicKey{key})[0]
+}
+
+// 检查一批密钥，除单个密钥的检查项外，使用批量GCD检查密钥间的公共素因子
+func (s *RSAAudit) CheckBatch(keys []PublicKey) []*RSAAuditResult {
+	results := make([]*RSAAuditResult, len(keys))
+	moduli := make([]*big.Int, 0, len(keys))
+	indexes := make([]int, 0, len(keys))
+	for index, key := range keys {
+		result := &RSAAuditResult{Index: index, Issues: make([]*RSAAuditIssue, 0)}
+		results[index] = result
+
+		rsaKey, err := s.rsaPublicKey(key)
+		if err != nil {
+			result.addIssue(RSAAuditInvalid, "%v", err)
+			continue
+		}
+		result.Length = rsaKey.N.BitLen()
+		s.check(rsaKey, result)
+
+		moduli = append(moduli, rsaKey.N)
+		indexes = append(indexes, index)
+	}
+
+	if len(moduli) > 1 {
+		s.checkSharedFactors(moduli, indexes, results)
+	}
+
+	return results
+}
+
+func (s *RSAAudit) rsaPublicKey(key PublicKey) (*rsa.PublicKey, error) {
+	if key == nil {
+		return nil, fmt.Errorf("invalid key: nil")
+	}
+	pub, err := key.CryptoPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("not a rsa public key: %T", pub)
+	}
+	if rsaKey.N == nil || rsaKey.N.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid modulus")
+	}
+
+	return rsaKey, nil
+}
+
+func (s *RSAAudit) check(key *rsa.PublicKey, result *RSAAuditResult) {
+	minLength := s.MinLength
+	if minLength < 1 {
+		minLength = 2048
+	}
+	if result.Length < minLength {
+		result.addIssue(RSAAuditWeakSize, "modulus size %d is less than %d", result.Length, minLength)
+	}
+
+	if key.E != 65537 {
+		if key.E < 3 || key.E%2 == 0 {
+			result.addIssue(RSAAuditExponent, "invalid public exponent %d", key.E)
+		} else {
+			result.addIssue(RSAAuditExponent, "unusual public exponent %d, expected 65537", key.E)
+		}
+	}
+
+	if rocaVulnerable(key.N) {
+		result.addIssue(RSAAuditRoca, "modulus has ROCA fingerprint (CVE-2017-15361)")
+	}
+
+	rounds := s.FermatRounds
+	if rounds == 0 {
+		rounds = 100
+	}
+	if rounds > 0 {
+		if p := fermatFactor(key.N, rounds); p != nil {
+			result.addIssue(RSAAuditFermat, "modulus factored by fermat method, primes are too close")
+		}
+	}
+}
+
+// 批量GCD，参考：Heninger et al., Mining Your Ps and Qs
+func (s *RSAAudit) checkSharedFactors(moduli []*big.Int, indexes []int, results []*RSAAuditResult) {
+	tree := productTree(moduli)
+	remainders := remainderTree(tree, moduli)
+
+	shared := make([]int, 0)
+	for i, n := range moduli {
+		// gcd(N, (P mod N^2) / N)
+		r := new(big.Int).Div(remainders[i], n)
+		if new(big.Int).GCD(nil, nil, r, n).Cmp(big.NewInt(1)) != 0 {
+			shared = append(shared, i)
+		}
+	}
+
+	// 仅对存在公共因子的密钥两两比较，确定对应关系
+	for _, i := range shared {
+		others := make([]string, 0)
+		for _, j := range shared {
+			if i == j {
+				continue
+			}
+			if new(big.Int).GCD(nil, nil, moduli[i], moduli[j]).Cmp(big.NewInt(1)) != 0 {
+				others = append(others, fmt.Sprint(indexes[j]))
+			}
+		}
+		results[indexes[i]].addIssue(RSAAuditSharedFactor, "modulus shares a prime factor with key %s", strings.Join(others, ", "))
+	}
+}
+
+func productTree(values []*big.Int) [][]*big.Int {
+	tree := [][]*big.Int{values}
+	for level := values; len(level) > 1; {
+		next := make([]*big.Int, (len(level)+1)/2)
+		for i := range next {
+			if 2*i+1 < len(level) {
+				next[i] = new(big.Int).Mul(level[2*i], level[2*i+1])
+			} else {
+				next[i] = level[2*i]
+			}
+		}
+		tree = append(tree, next)
+		level = next
+	}
+
+	return tree
+}
+
+// 自顶向下计算 P mod N^2
+func remainderTree(tree [][]*big.Int, values []*big.Int) []*big.Int {
+	remainders := tree[len(tree)-1]
+	for level := len(tree) - 2; level >= 0; level-- {
+		next := make([]*big.Int, len(tree[level]))
+		for i, v := range tree[level] {
+			square := new(big.Int).Mul(v, v)
+			next[i] = new(big.Int).Mod(remainders[i/2], square)
+		}
+		remainders = next
+	}
+
+	return remainders
+}
+
+// 费马分解：N = a^2 - b^2 = (a+b)(a-b)，从a = ceil(sqrt(N))开始尝试
+func fermatFactor(n *big.Int, rounds int) *big.Int {
+	if n.Bit(0) == 0 {
+		return nil
+	}
+	a := new(big.Int).Sqrt(n)
+	if new(big.Int).Mul(a, a).Cmp(n) < 0 {
+		a.Add(a, big.NewInt(1))
+	}
+	b2 := new(big.Int)
+	b := new(big.Int)
+	for i := 0; i < rounds; i++ {
+		b2.Mul(a, a)
+		b2.Sub(b2, n)
+		b.Sqrt(b2)
+		if new(big.Int).Mul(b, b).Cmp(b2) == 0 {
+			p := new(big.Int).Sub(a, b)
+			if p.Cmp(big.NewInt(1)) > 0 {
+				return p
+			}
+		}
+		a.Add(a, big.NewInt(1))
+	}
+
+	return nil
+}
+
+// ROCA检测：易受攻击的密钥模N对各小素数r的余数均属于65537生成的乘法子群
+// 参考：Nemec et al., The Return of Coppersmith's Attack (CCS 2017)
+var rocaPrimes = []int64{
+	3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37, 41, 43, 47, 53, 59, 61, 67, 71,
+	73, 79, 83, 89, 97, 101, 103, 107, 109, 113, 127, 131, 137, 139, 149, 151,
+	157, 163, 167,
+}
+
+var rocaSubgroups = func() []map[int64]bool {
+	subgroups := make([]map[int64]bool, len(rocaPrimes))
+	for i, r := range rocaPrimes {
+		subgroup := make(map[int64]bool)
+		g := int64(65537) % r
+		for v := int64(1); !subgroup[v]; v = v * g % r {
+			subgroup[v] = true
+		}
+		subgroups[i] = subgroup
+	}
+
+	return subgroups
+}()
+
+func rocaVulnerable(n *big.Int) bool {
+	m := new(big.Int)
+	for i, r := range rocaPrimes {
+		m.Mod(n, big.NewInt(r))
+		if !rocaSubgroups[i][m.Int64()] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,177 @@
+package certificate
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"fmt"
+	"github.com/csby/security/hash"
+	"io"
+	"math/big"
+)
+
+const (
+	RSABlindPSS     = 0 // RSABSSA-SHA384-PSS：盐长度48
+	RSABlindPSSZero = 1 // RSABSSA-SHA384-PSSZERO：不使用盐，签名为确定性的
+)
+
+const (
+	rsaBlindSaltLength   = 48
+	rsaBlindPrefixLength = 32
+)
+
+// RSA盲签名(RSABSSA)，参考：RFC 9474
+// 流程：客户端Prepare、Blind -> 签名方BlindSign -> 客户端Finalize -> 任意方Verify
+// 签名方无法将最终签名与签名请求关联
+type RSABlind struct {
+	Variant    int  // RSABlindPSS(default) or RSABlindPSSZero
+	Randomized bool // 是否在消息前添加32字节随机前缀，防止签名方通过消息内容关联请求
+}
+
+// 变体名称，如：RSABSSA-SHA384-PSS-Randomized
+func (s *RSABlind) Name() string {
+	name := "RSABSSA-SHA384-PSS"
+	if s.Variant == RSABlindPSSZero {
+		name = "RSABSSA-SHA384-PSSZERO"
+	}
+	if s.Randomized {
+		return name + "-Randomized"
+	}
+
+	return name + "-Deterministic"
+}
+
+// 预处理消息，返回用于盲化、完成及验证的消息
+func (s *RSABlind) Prepare(msg []byte) ([]byte, error) {
+	if !s.Randomized {
+		return msg, nil
+	}
+
+	prefix := make([]byte, rsaBlindPrefixLength)
+	_, err := io.ReadFull(rand.Reader, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(prefix, msg...), nil
+}
+
+// 盲化消息，返回发送给签名方的盲化消息及用于完成签名的逆元(须保密)
+func (s *RSABlind) Blind(public *RSAPublic, msg []byte) (blindedMsg, inv []byte, err error) {
+	if public == nil || public.key == nil {
+		return nil, nil, fmt.Errorf("invalid public key")
+	}
+	key := public.key
+
+	salt := make([]byte, s.saltLength())
+	_, err = io.ReadFull(rand.Reader, salt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var r, rInv *big.Int
+	for {
+		r, err = rand.Int(rand.Reader, key.N)
+		if err != nil {
+			return nil, nil, err
+		}
+		if r.Sign() == 0 {
+			continue
+		}
+		rInv = new(big.Int).ModInverse(r, key.N)
+		if rInv != nil {
+			break
+		}
+	}
+
+	blindedMsg, err = rsaBlind(key, msg, salt, r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return blindedMsg, leftPad(rInv.Bytes(), key.Size()), nil
+}
+
+// 签名方对盲化消息签名
+func (s *RSABlind) BlindSign(private *RSAPrivate, blindedMsg []byte) ([]byte, error) {
+	if private == nil {
+		return nil, fmt.Errorf("invalid private key")
+	}
+
+	return private.BlindSign(blindedMsg)
+}
+
+// 使用盲化时的逆元去除盲签名中的盲因子，并验证得到的签名
+func (s *RSABlind) Finalize(public *RSAPublic, msg, blindSig, inv []byte) ([]byte, error) {
+	if public == nil || public.key == nil {
+		return nil, fmt.Errorf("invalid public key")
+	}
+	key := public.key
+	if len(blindSig) != key.Size() || len(inv) != key.Size() {
+		return nil, fmt.Errorf("rsa: invalid blind signature length")
+	}
+
+	z := new(big.Int).SetBytes(blindSig)
+	sig := z.Mul(z, new(big.Int).SetBytes(inv))
+	sig.Mod(sig, key.N)
+	signature := leftPad(sig.Bytes(), key.Size())
+
+	err := s.Verify(public, msg, signature)
+	if err != nil {
+		return nil, err
+	}
+
+	return signature, nil
+}
+
+// 验证签名，msg为Prepare返回的消息
+func (s *RSABlind) Verify(public *RSAPublic, msg, signature []byte) error {
+	if public == nil {
+		return fmt.Errorf("invalid public key")
+	}
+	opts := &RSAPSSOptions{SaltLength: rsaBlindSaltLength}
+	if s.Variant == RSABlindPSSZero {
+		opts.SaltLength = RSAPSSSaltLengthZero
+	}
+
+	return public.VerifyPSS(msg, signature, &hash.Sha384{}, opts)
+}
+
+func (s *RSABlind) saltLength() int {
+	if s.Variant == RSABlindPSSZero {
+		return 0
+	}
+
+	return rsaBlindSaltLength
+}
+
+// RSA盲签名：对盲化消息进行原始RSA签名(RSASP1)，不做任何填充
+func (s *RSAPrivate) BlindSign(blindedMsg []byte) ([]byte, error) {
+	if s.key == nil {
+		return nil, fmt.Errorf("invalid key")
+	}
+	if len(blindedMsg) != s.key.Size() {
+		return nil, fmt.Errorf("rsa: invalid blinded message length")
+	}
+
+	return rsaRawSign(s.key, blindedMsg)
+}
+
+// blinded_msg = EMSA-PSS-ENCODE(msg) * r^e mod n
+func rsaBlind(key *rsa.PublicKey, msg, salt []byte, r *big.Int) ([]byte, error) {
+	mHash := sha512.Sum384(msg)
+	em, err := emsaPSSEncode(mHash[:], key.N.BitLen()-1, salt, sha512.New384(), sha512.New384())
+	if err != nil {
+		return nil, err
+	}
+
+	m := new(big.Int).SetBytes(em)
+	if new(big.Int).GCD(nil, nil, m, key.N).Cmp(big.NewInt(1)) != 0 {
+		return nil, fmt.Errorf("rsa: invalid input")
+	}
+	x := new(big.Int).Exp(r, big.NewInt(int64(key.E)), key.N)
+	z := x.Mul(x, m)
+	z.Mod(z, key.N)
+
+	return leftPad(z.Bytes(), key.Size()), nil
+}
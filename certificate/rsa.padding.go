@@ -0,0 +1,144 @@
+package certificate
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"github.com/csby/security/hash"
+)
+
+const (
+	RSAPaddingPKCS1v15 = 0 // RSAES-PKCS1-v1_5(默认)
+	RSAPaddingOAEP     = 1 // RSAES-OAEP
+)
+
+// RSA加密填充方案
+type RSAPadding struct {
+	Scheme int       // RSAPaddingPKCS1v15(default) or RSAPaddingOAEP
+	Hash   hash.Hash // OAEP哈希算法(同时用于MGF1)，默认SHA256
+	Label  []byte    // OAEP标签，可选
+}
+
+func (s *RSAPadding) oaepHash() (hash.Hash, error) {
+	h := s.Hash
+	if h == nil {
+		h = &hash.Sha256{}
+	}
+	if !h.Type().Available() {
+		return nil, fmt.Errorf("not support oaep hash: %T", h)
+	}
+
+	return h, nil
+}
+
+// 单次可加密的最大明文长度
+func (s *RSAPadding) maxSize(key *rsa.PublicKey) (int, error) {
+	switch s.Scheme {
+	case RSAPaddingPKCS1v15:
+		return key.Size() - 11, nil
+	case RSAPaddingOAEP:
+		h, err := s.oaepHash()
+		if err != nil {
+			return 0, err
+		}
+		return key.Size() - 2*h.Type().Size() - 2, nil
+	}
+
+	return 0, fmt.Errorf("not support padding scheme: %d", s.Scheme)
+}
+
+func (s *RSAPadding) encrypt(key *rsa.PublicKey, data []byte) ([]byte, error) {
+	if s.Scheme == RSAPaddingOAEP {
+		h, err := s.oaepHash()
+		if err != nil {
+			return nil, err
+		}
+		return rsa.EncryptOAEP(h.Type().New(), rand.Reader, key, data, s.Label)
+	}
+
+	return rsa.EncryptPKCS1v15(rand.Reader, key, data)
+}
+
+func (s *RSAPadding) decrypt(key *rsa.PrivateKey, data []byte) ([]byte, error) {
+	if s.Scheme == RSAPaddingOAEP {
+		h, err := s.oaepHash()
+		if err != nil {
+			return nil, err
+		}
+		return rsa.DecryptOAEP(h.Type().New(), rand.Reader, key, data, s.Label)
+	}
+
+	return rsa.DecryptPKCS1v15(rand.Reader, key, data)
+}
+
+// 分段加密，超过单次最大长度的数据按最大长度分段后依次加密
+func rsaEncryptBlocks(key *rsa.PublicKey, data []byte, padding *RSAPadding) ([]byte, error) {
+	if key == nil {
+		return nil, fmt.Errorf("invalid key")
+	}
+	if padding == nil {
+		padding = &RSAPadding{}
+	}
+	maxSize, err := padding.maxSize(key)
+	if err != nil {
+		return nil, err
+	}
+	if maxSize < 1 {
+		return nil, fmt.Errorf("key too short for padding scheme")
+	}
+
+	var buf bytes.Buffer
+	dataLength := len(data)
+	for offset := 0; offset < dataLength; offset += maxSize {
+		end := offset + maxSize
+		if end > dataLength {
+			end = dataLength
+		}
+		vav, err := padding.encrypt(key, data[offset:end])
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = buf.Write(vav)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// 分段解密，密文按密钥长度分段后依次解密
+func rsaDecryptBlocks(key *rsa.PrivateKey, data []byte, padding *RSAPadding) ([]byte, error) {
+	if key == nil {
+		return nil, fmt.Errorf("invalid key")
+	}
+	if padding == nil {
+		padding = &RSAPadding{}
+	}
+	if padding.Scheme != RSAPaddingPKCS1v15 && padding.Scheme != RSAPaddingOAEP {
+		return nil, fmt.Errorf("not support padding scheme: %d", padding.Scheme)
+	}
+
+	var buf bytes.Buffer
+	blockSize := key.Size()
+	dataLength := len(data)
+	for offset := 0; offset < dataLength; offset += blockSize {
+		end := offset + blockSize
+		if end > dataLength {
+			end = dataLength
+		}
+		vav, err := padding.decrypt(key, data[offset:end])
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = buf.Write(vav)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
@@ -1,23 +1,28 @@
 package certificate
 
 import (
-	"bytes"
+	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
 	"github.com/csby/security/hash"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
 )
 
+// RSA私钥，实现crypto.Signer及crypto.Decrypter接口
+// 不兼容变更：Public()、Sign(data, h)、Decrypt(data)分别更名为PublicKey()、SignPKCS1v15(data, h)、DecryptPKCS1v15(data)，
+// Public、Sign、Decrypt改为标准库接口的方法，ECDSAPrivate、Ed25519Private的Public()同样更名为PublicKey()
 type RSAPrivate struct {
 	key *rsa.PrivateKey
 
-	Format string // pkcs1(default) or pkcs8
+	Format     string // pkcs1(default) or pkcs8
+	Iterations int    // 使用密码保存时PBKDF2迭代次数，默认DefaultPkcs8Iterations
 }
 
 func (s *RSAPrivate) Key() *rsa.PrivateKey {
@@ -32,13 +37,14 @@ func (s *RSAPrivate) Length() int {
 	return s.key.N.BitLen()
 }
 
-func (s *RSAPrivate) Public() (*RSAPublic, error) {
+// 获取公钥，Public方法返回标准库公钥
+func (s *RSAPrivate) PublicKey() (*RSAPublic, error) {
 	if s.key == nil {
 		return nil, fmt.Errorf("invalid private key")
 	}
 
 	data, err := x509.MarshalPKIXPublicKey(&s.key.PublicKey)
-	if err != err {
+	if err != nil {
 		return nil, err
 	}
 	key, err := x509.ParsePKIXPublicKey(data)
@@ -51,46 +57,66 @@ func (s *RSAPrivate) Public() (*RSAPublic, error) {
 	}, nil
 }
 
-func (s *RSAPrivate) Decrypt(data []byte) ([]byte, error) {
-	if s.key == nil {
-		return nil, fmt.Errorf("invalid key")
+func (s *RSAPrivate) CryptoSigner() (crypto.Signer, error) {
+	if s == nil || s.key == nil {
+		return nil, fmt.Errorf("invalid private key")
 	}
 
-	var buf bytes.Buffer
-	maxSize := s.key.N.BitLen() / 8
-	dataLength := len(data)
-	count := dataLength / maxSize
-	offset := 0
-	for index := 1; index <= count; index++ {
-		vav, err := rsa.DecryptPKCS1v15(rand.Reader, s.key, data[offset:offset+maxSize])
-		if err != nil {
-			return nil, err
-		}
+	return s.key, nil
+}
 
-		_, err = buf.Write(vav)
-		if err != nil {
-			return nil, err
-		}
+// 实现crypto.Signer及crypto.Decrypter接口，可直接用于tls.Certificate等标准库接口
+func (s *RSAPrivate) Public() crypto.PublicKey {
+	if s == nil || s.key == nil {
+		return nil
+	}
+
+	return s.key.Public()
+}
 
-		offset += maxSize
+// crypto.Signer：对摘要签名，opts为crypto.Hash时使用PKCS#1 v1.5，为*rsa.PSSOptions时使用PSS
+func (s *RSAPrivate) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if s == nil || s.key == nil {
+		return nil, fmt.Errorf("invalid private key")
 	}
 
-	if dataLength > offset {
-		vav, err := rsa.DecryptPKCS1v15(rand.Reader, s.key, data[offset:dataLength])
-		if err != nil {
-			return nil, err
-		}
+	return s.key.Sign(rand, digest, opts)
+}
 
-		_, err = buf.Write(vav)
-		if err != nil {
-			return nil, err
-		}
+// crypto.Decrypter：opts为nil或*rsa.PKCS1v15DecryptOptions时使用PKCS#1 v1.5，为*rsa.OAEPOptions时使用OAEP
+func (s *RSAPrivate) Decrypt(rand io.Reader, msg []byte, opts crypto.DecrypterOpts) ([]byte, error) {
+	if s == nil || s.key == nil {
+		return nil, fmt.Errorf("invalid private key")
+	}
+
+	return s.key.Decrypt(rand, msg, opts)
+}
+
+func (s *RSAPrivate) CryptoPublicKey() (crypto.PublicKey, error) {
+	if s == nil || s.key == nil {
+		return nil, fmt.Errorf("invalid private key")
 	}
 
-	return buf.Bytes(), nil
+	return s.key.Public(), nil
+}
+
+// 使用PKCS#1 v1.5填充解密，密文按密钥长度分段解密
+func (s *RSAPrivate) DecryptPKCS1v15(data []byte) ([]byte, error) {
+	return rsaDecryptBlocks(s.key, data, &RSAPadding{Scheme: RSAPaddingPKCS1v15})
+}
+
+// 使用OAEP填充解密，h及label须与加密时一致
+func (s *RSAPrivate) DecryptOAEP(data []byte, h hash.Hash, label []byte) ([]byte, error) {
+	return rsaDecryptBlocks(s.key, data, &RSAPadding{Scheme: RSAPaddingOAEP, Hash: h, Label: label})
+}
+
+// 使用指定的填充方案解密，padding为空时使用PKCS#1 v1.5
+func (s *RSAPrivate) DecryptWithPadding(data []byte, padding *RSAPadding) ([]byte, error) {
+	return rsaDecryptBlocks(s.key, data, padding)
 }
 
-func (s *RSAPrivate) Sign(data []byte, h hash.Hash) ([]byte, error) {
+// 使用PKCS#1 v1.5签名，h默认为MD5，可使用RSAPublic.Verify验证
+func (s *RSAPrivate) SignPKCS1v15(data []byte, h hash.Hash) ([]byte, error) {
 	if h == nil {
 		h = &hash.Md5{}
 	}
@@ -157,48 +183,42 @@ func (s *RSAPrivate) FromData(data []byte, password string) error {
 		return fmt.Errorf("invalid private key file")
 	}
 
-	blockData := block.Bytes
-	if x509.IsEncryptedPEMBlock(block) {
-		decodedBlockData, err := x509.DecryptPEMBlock(block, []byte(password))
-		if err != nil {
-			return fmt.Errorf("password invalid: %v", err)
-		}
-		blockData = decodedBlockData
-	}
-
-	key, err := x509.ParsePKCS1PrivateKey(blockData)
+	key, err := decodePrivateKeyBlock(block, password)
 	if err != nil {
-		keyResult, err := x509.ParsePKCS8PrivateKey(blockData)
-		if err != nil {
-			return err
-		}
-		key = keyResult.(*rsa.PrivateKey)
+		return err
 	}
-	s.key = key
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return fmt.Errorf("not a rsa private key: %T", key)
+	}
+	s.key = rsaKey
 
 	return nil
 }
 
-func (s *RSAPrivate) encode(password string) (block *pem.Block, err error) {
-	data := x509.MarshalPKCS1PrivateKey(s.key)
-	if strings.ToLower(s.Format) == "pkcs8" {
-		data, err = x509.MarshalPKCS8PrivateKey(s.key)
-		if err != nil {
-			return
-		}
+// 未加密时按Format输出RSA PRIVATE KEY(PKCS#1)或PRIVATE KEY(PKCS#8)
+// 使用密码时输出ENCRYPTED PRIVATE KEY(PKCS#8, PBES2)
+func (s *RSAPrivate) encode(password string) (*pem.Block, error) {
+	if s.key == nil {
+		return nil, fmt.Errorf("invalid key")
 	}
 
-	if len(password) > 0 {
-		block, err = x509.EncryptPEMBlock(rand.Reader,
-			"RSA PRIVATE KEY",
-			data,
-			[]byte(password),
-			x509.PEMCipherAES256)
-	} else {
-		block = &pem.Block{
-			Type:  "RSA PRIVATE KEY",
-			Bytes: data,
+	if len(password) > 0 || strings.ToLower(s.Format) == "pkcs8" {
+		data, err := x509.MarshalPKCS8PrivateKey(s.key)
+		if err != nil {
+			return nil, err
+		}
+		if len(password) > 0 {
+			return encryptPkcs8Block(data, password, s.Iterations)
 		}
+		return &pem.Block{
+			Type:  "PRIVATE KEY",
+			Bytes: data,
+		}, nil
 	}
-	return
+
+	return &pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(s.key),
+	}, nil
 }
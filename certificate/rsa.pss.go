@@ -0,0 +1,253 @@
+package certificate
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"github.com/csby/security/hash"
+	gohash "hash"
+	"io"
+)
+
+const (
+	RSAPSSSaltLengthEqualsHash = 0  // 盐长度等于摘要长度(默认)
+	RSAPSSSaltLengthAuto       = -1 // 签名时使用最大长度，验证时自动检测
+	RSAPSSSaltLengthZero       = -2 // 不使用盐(确定性签名)
+)
+
+// RSASSA-PSS签名参数，参考：RFC 8017 8.1、9.1
+type RSAPSSOptions struct {
+	SaltLength int       // 盐长度(字节)，或RSAPSSSaltLengthEqualsHash、RSAPSSSaltLengthAuto、RSAPSSSaltLengthZero
+	MGFHash    hash.Hash // MGF1使用的哈希算法，默认与消息摘要算法相同
+}
+
+func (s *RSAPSSOptions) saltLength(key *rsa.PublicKey, h hash.Hash, signing bool) int {
+	switch s.SaltLength {
+	case RSAPSSSaltLengthEqualsHash:
+		return h.Type().Size()
+	case RSAPSSSaltLengthZero:
+		return 0
+	case RSAPSSSaltLengthAuto:
+		if signing {
+			return (key.N.BitLen()-1+7)/8 - 2 - h.Type().Size()
+		}
+		return -1
+	}
+
+	return s.SaltLength
+}
+
+func (s *RSAPSSOptions) mgfHash(h hash.Hash) (hash.Hash, error) {
+	mgf := s.MGFHash
+	if mgf == nil {
+		mgf = h
+	}
+	if !mgf.Type().Available() {
+		return nil, fmt.Errorf("not support mgf hash: %T", mgf)
+	}
+
+	return mgf, nil
+}
+
+// 使用RSASSA-PSS签名，h默认为SHA256，opts为空时盐长度等于摘要长度
+func (s *RSAPrivate) SignPSS(data []byte, h hash.Hash, opts *RSAPSSOptions) ([]byte, error) {
+	if s.key == nil {
+		return nil, fmt.Errorf("invalid key")
+	}
+	if h == nil {
+		h = &hash.Sha256{}
+	}
+	if opts == nil {
+		opts = &RSAPSSOptions{}
+	}
+	if !h.Type().Available() {
+		return nil, fmt.Errorf("not support hash: %T", h)
+	}
+	hashed, err := h.Hash(data)
+	if err != nil {
+		return nil, err
+	}
+	mgf, err := opts.mgfHash(h)
+	if err != nil {
+		return nil, err
+	}
+
+	saltLength := opts.saltLength(&s.key.PublicKey, h, true)
+	if saltLength < 0 {
+		return nil, fmt.Errorf("rsa: invalid salt length: %d", opts.SaltLength)
+	}
+	if mgf.Type() == h.Type() && saltLength > 0 {
+		return rsa.SignPSS(rand.Reader, s.key, h.Type(), hashed, &rsa.PSSOptions{SaltLength: saltLength})
+	}
+
+	// 标准库不支持独立的MGF哈希及零长度盐，使用自定义编码
+	salt := make([]byte, saltLength)
+	_, err = io.ReadFull(rand.Reader, salt)
+	if err != nil {
+		return nil, err
+	}
+	em, err := emsaPSSEncode(hashed, s.key.N.BitLen()-1, salt, h.Type().New(), mgf.Type().New())
+	if err != nil {
+		return nil, err
+	}
+
+	return rsaRawSign(s.key, em)
+}
+
+// 验证RSASSA-PSS签名，h及opts须与签名时一致
+func (s *RSAPublic) VerifyPSS(data []byte, signature []byte, h hash.Hash, opts *RSAPSSOptions) error {
+	if s.key == nil {
+		return fmt.Errorf("invalid key")
+	}
+	if h == nil {
+		h = &hash.Sha256{}
+	}
+	if opts == nil {
+		opts = &RSAPSSOptions{}
+	}
+	if !h.Type().Available() {
+		return fmt.Errorf("not support hash: %T", h)
+	}
+	hashed, err := h.Hash(data)
+	if err != nil {
+		return err
+	}
+	mgf, err := opts.mgfHash(h)
+	if err != nil {
+		return err
+	}
+
+	saltLength := opts.saltLength(s.key, h, false)
+	if saltLength < 0 && saltLength != RSAPSSSaltLengthAuto {
+		return fmt.Errorf("rsa: invalid salt length: %d", opts.SaltLength)
+	}
+	if mgf.Type() == h.Type() && saltLength != 0 {
+		goSaltLength := saltLength
+		if saltLength == RSAPSSSaltLengthAuto {
+			goSaltLength = rsa.PSSSaltLengthAuto
+		}
+		return rsa.VerifyPSS(s.key, h.Type(), hashed, signature, &rsa.PSSOptions{SaltLength: goSaltLength})
+	}
+
+	m, err := rsaRawVerify(s.key, signature)
+	if err != nil {
+		return err
+	}
+	emBits := s.key.N.BitLen() - 1
+	emLen := (emBits + 7) / 8
+	if len(m) > emLen {
+		// 编码长度比模长少1字节时，首字节须为0
+		if m[0] != 0 {
+			return fmt.Errorf("rsa: verification error")
+		}
+		m = m[len(m)-emLen:]
+	}
+
+	return emsaPSSVerify(hashed, m, emBits, saltLength, h.Type().New(), mgf.Type().New())
+}
+
+// EMSA-PSS编码，参考：RFC 8017 9.1.1
+func emsaPSSEncode(mHash []byte, emBits int, salt []byte, h, mgf gohash.Hash) ([]byte, error) {
+	hLen := h.Size()
+	sLen := len(salt)
+	emLen := (emBits + 7) / 8
+	if len(mHash) != hLen {
+		return nil, fmt.Errorf("rsa: input must be hashed message")
+	}
+	if emLen < hLen+sLen+2 {
+		return nil, fmt.Errorf("rsa: key size too small for PSS signature")
+	}
+
+	// H = Hash(0x00*8 || mHash || salt)
+	h.Reset()
+	h.Write(make([]byte, 8))
+	h.Write(mHash)
+	h.Write(salt)
+	hashed := h.Sum(nil)
+
+	// DB = PS || 0x01 || salt
+	em := make([]byte, emLen)
+	db := em[:emLen-hLen-1]
+	db[emLen-sLen-hLen-2] = 0x01
+	copy(db[emLen-sLen-hLen-1:], salt)
+
+	mgf1XOR(db, mgf, hashed)
+	db[0] &= 0xff >> uint(8*emLen-emBits)
+
+	copy(em[emLen-hLen-1:], hashed)
+	em[emLen-1] = 0xbc
+
+	return em, nil
+}
+
+// EMSA-PSS验证，参考：RFC 8017 9.1.2，sLen为-1时自动检测盐长度
+func emsaPSSVerify(mHash, em []byte, emBits, sLen int, h, mgf gohash.Hash) error {
+	hLen := h.Size()
+	emLen := (emBits + 7) / 8
+	if len(mHash) != hLen || len(em) != emLen {
+		return fmt.Errorf("rsa: verification error")
+	}
+	if emLen < hLen+sLen+2 || em[emLen-1] != 0xbc {
+		return fmt.Errorf("rsa: verification error")
+	}
+
+	db := make([]byte, emLen-hLen-1)
+	copy(db, em[:emLen-hLen-1])
+	hashed := em[emLen-hLen-1 : emLen-1]
+	if db[0]&^(0xff>>uint(8*emLen-emBits)) != 0 {
+		return fmt.Errorf("rsa: verification error")
+	}
+	mgf1XOR(db, mgf, hashed)
+	db[0] &= 0xff >> uint(8*emLen-emBits)
+
+	if sLen < 0 {
+		psLen := bytes.IndexByte(db, 0x01)
+		if psLen < 0 {
+			return fmt.Errorf("rsa: verification error")
+		}
+		sLen = len(db) - psLen - 1
+	}
+	psLen := emLen - hLen - sLen - 2
+	for _, b := range db[:psLen] {
+		if b != 0 {
+			return fmt.Errorf("rsa: verification error")
+		}
+	}
+	if db[psLen] != 0x01 {
+		return fmt.Errorf("rsa: verification error")
+	}
+	salt := db[len(db)-sLen:]
+
+	h.Reset()
+	h.Write(make([]byte, 8))
+	h.Write(mHash)
+	h.Write(salt)
+	if !bytes.Equal(h.Sum(nil), hashed) {
+		return fmt.Errorf("rsa: verification error")
+	}
+
+	return nil
+}
+
+// MGF1掩码生成并与out异或，参考：RFC 8017 B.2.1
+func mgf1XOR(out []byte, h gohash.Hash, seed []byte) {
+	counter := make([]byte, 4)
+	done := 0
+	for done < len(out) {
+		h.Reset()
+		h.Write(seed)
+		h.Write(counter)
+		digest := h.Sum(nil)
+		for i := 0; i < len(digest) && done < len(out); i++ {
+			out[done] ^= digest[i]
+			done++
+		}
+		for i := 3; i >= 0; i-- {
+			counter[i]++
+			if counter[i] != 0 {
+				break
+			}
+		}
+	}
+}
@@ -1,8 +1,7 @@
 package certificate
 
 import (
-	"bytes"
-	"crypto/rand"
+	"crypto"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
@@ -28,6 +27,14 @@ func (s *RSAPublic) Length() int {
 	return s.key.N.BitLen()
 }
 
+func (s *RSAPublic) CryptoPublicKey() (crypto.PublicKey, error) {
+	if s == nil || s.key == nil {
+		return nil, fmt.Errorf("invalid public key")
+	}
+
+	return s.key, nil
+}
+
 func (s *RSAPublic) Base64() string {
 	data, err := x509.MarshalPKIXPublicKey(s.key)
 	if err != nil {
@@ -37,43 +44,35 @@ func (s *RSAPublic) Base64() string {
 	return encoding.ToBase64String(data)
 }
 
-func (s *RSAPublic) Encrypt(data []byte) ([]byte, error) {
+// 公钥(PKIX, DER)使用指定的编码转换为字符串
+func (s *RSAPublic) EncodeToString(encoder encoding.Encoder) (string, error) {
 	if s.key == nil {
-		return nil, fmt.Errorf("invalid key")
+		return "", fmt.Errorf("invalid key")
 	}
-
-	var buf bytes.Buffer
-	maxSize := s.key.N.BitLen()/8 - 11
-	dataLength := len(data)
-	count := dataLength / maxSize
-	offset := 0
-	for index := 1; index <= count; index++ {
-		vav, err := rsa.EncryptPKCS1v15(rand.Reader, s.key, data[offset:offset+maxSize])
-		if err != nil {
-			return nil, err
-		}
-
-		_, err = buf.Write(vav)
-		if err != nil {
-			return nil, err
-		}
-
-		offset += maxSize
+	if encoder == nil {
+		return "", fmt.Errorf("invalid encoder: nil")
+	}
+	data, err := x509.MarshalPKIXPublicKey(s.key)
+	if err != nil {
+		return "", err
 	}
 
-	if dataLength > offset {
-		vav, err := rsa.EncryptPKCS1v15(rand.Reader, s.key, data[offset:dataLength])
-		if err != nil {
-			return nil, err
-		}
+	return encoder.EncodeToString(data), nil
+}
 
-		_, err = buf.Write(vav)
-		if err != nil {
-			return nil, err
-		}
-	}
+// 使用PKCS#1 v1.5填充加密，超过单次最大长度的数据分段加密
+func (s *RSAPublic) Encrypt(data []byte) ([]byte, error) {
+	return rsaEncryptBlocks(s.key, data, &RSAPadding{Scheme: RSAPaddingPKCS1v15})
+}
+
+// 使用OAEP填充加密，h默认为SHA256，label可为空
+func (s *RSAPublic) EncryptOAEP(data []byte, h hash.Hash, label []byte) ([]byte, error) {
+	return rsaEncryptBlocks(s.key, data, &RSAPadding{Scheme: RSAPaddingOAEP, Hash: h, Label: label})
+}
 
-	return buf.Bytes(), nil
+// 使用指定的填充方案加密，padding为空时使用PKCS#1 v1.5
+func (s *RSAPublic) EncryptWithPadding(data []byte, padding *RSAPadding) ([]byte, error) {
+	return rsaEncryptBlocks(s.key, data, padding)
 }
 
 func (s *RSAPublic) Verify(data []byte, signature []byte, h hash.Hash) error {
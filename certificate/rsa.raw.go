@@ -0,0 +1,81 @@
+package certificate
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"math/big"
+)
+
+// RSA原始私钥运算(RSASP1)：s = m^d mod n，使用随机数盲化防止时间侧信道，并校验结果
+func rsaRawSign(key *rsa.PrivateKey, em []byte) ([]byte, error) {
+	if key == nil {
+		return nil, fmt.Errorf("invalid key")
+	}
+	n := key.N
+	m := new(big.Int).SetBytes(em)
+	if m.Cmp(n) >= 0 {
+		return nil, fmt.Errorf("rsa: message representative out of range")
+	}
+
+	// 盲化：c = m * r^e mod n
+	var r *big.Int
+	var rInv *big.Int
+	for {
+		v, err := rand.Int(rand.Reader, n)
+		if err != nil {
+			return nil, err
+		}
+		if v.Sign() == 0 {
+			continue
+		}
+		rInv = new(big.Int).ModInverse(v, n)
+		if rInv != nil {
+			r = v
+			break
+		}
+	}
+	e := big.NewInt(int64(key.E))
+	c := new(big.Int).Exp(r, e, n)
+	c.Mul(c, m)
+	c.Mod(c, n)
+
+	sig := new(big.Int).Exp(c, key.D, n)
+	sig.Mul(sig, rInv)
+	sig.Mod(sig, n)
+
+	// 校验签名，防止计算错误泄露私钥
+	check := new(big.Int).Exp(sig, e, n)
+	if check.Cmp(m) != 0 {
+		return nil, fmt.Errorf("rsa: internal error")
+	}
+
+	return leftPad(sig.Bytes(), key.Size()), nil
+}
+
+// RSA原始公钥运算(RSAVP1)：m = s^e mod n
+func rsaRawVerify(key *rsa.PublicKey, sig []byte) ([]byte, error) {
+	if key == nil {
+		return nil, fmt.Errorf("invalid key")
+	}
+	if len(sig) != key.Size() {
+		return nil, fmt.Errorf("rsa: invalid signature length")
+	}
+	s := new(big.Int).SetBytes(sig)
+	if s.Cmp(key.N) >= 0 {
+		return nil, fmt.Errorf("rsa: signature representative out of range")
+	}
+	m := new(big.Int).Exp(s, big.NewInt(int64(key.E)), key.N)
+
+	return leftPad(m.Bytes(), key.Size()), nil
+}
+
+func leftPad(data []byte, size int) []byte {
+	if len(data) >= size {
+		return data
+	}
+	padded := make([]byte, size)
+	copy(padded[size-len(data):], data)
+
+	return padded
+}
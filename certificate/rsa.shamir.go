@@ -0,0 +1,40 @@
+package certificate
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"github.com/csby/security/shamir"
+)
+
+// 将私钥(PKCS#8, DER)分割为count份，任意threshold份可恢复私钥，用于多人共同保管
+func (s *RSAPrivate) ToShares(threshold, count int) ([]*shamir.Share, error) {
+	if s.key == nil {
+		return nil, fmt.Errorf("invalid key")
+	}
+	data, err := x509.MarshalPKCS8PrivateKey(s.key)
+	if err != nil {
+		return nil, err
+	}
+
+	return shamir.Split(data, threshold, count)
+}
+
+// 使用不少于门限数的份额恢复私钥
+func (s *RSAPrivate) FromShares(shares []*shamir.Share) error {
+	data, err := shamir.Combine(shares)
+	if err != nil {
+		return err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(data)
+	if err != nil {
+		return err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return fmt.Errorf("not a rsa private key: %T", key)
+	}
+	s.key = rsaKey
+
+	return nil
+}
@@ -0,0 +1,369 @@
+package certificate
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// OpenSSH密钥格式，参考：RFC 4253 6.6、OpenSSH PROTOCOL.key
+const (
+	sshKeyTypeRSA        = "ssh-rsa"
+	sshPrivateKeyMagic   = "openssh-key-v1\x00"
+	sshPrivateKeyPemType = "OPENSSH PRIVATE KEY"
+)
+
+// 转换为OpenSSH公钥行(authorized_keys格式)，如：ssh-rsa AAAA... user@host
+func (s *RSAPublic) ToSSH(comment string) (string, error) {
+	if s.key == nil {
+		return "", fmt.Errorf("invalid key")
+	}
+
+	line := sshKeyTypeRSA + " " + base64.StdEncoding.EncodeToString(sshMarshalRSAPublic(s.key))
+	comment = strings.TrimSpace(comment)
+	if len(comment) > 0 {
+		line += " " + comment
+	}
+
+	return line, nil
+}
+
+// 从OpenSSH公钥行(authorized_keys格式，可包含选项前缀)加载公钥，返回注释
+func (s *RSAPublic) FromSSH(line string) (string, error) {
+	fields := strings.Fields(line)
+	for index, field := range fields {
+		if field != sshKeyTypeRSA {
+			continue
+		}
+		if index+1 >= len(fields) {
+			break
+		}
+		blob, err := base64.StdEncoding.DecodeString(fields[index+1])
+		if err != nil {
+			return "", fmt.Errorf("invalid ssh public key: %v", err)
+		}
+		key, err := sshParseRSAPublic(blob)
+		if err != nil {
+			return "", err
+		}
+		s.key = key
+
+		return strings.Join(fields[index+2:], " "), nil
+	}
+
+	return "", fmt.Errorf("invalid ssh public key: %s not found", sshKeyTypeRSA)
+}
+
+// OpenSSH公钥指纹，与ssh-keygen -l输出一致，如：SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8
+func (s *RSAPublic) SSHFingerprint() (string, error) {
+	if s.key == nil {
+		return "", fmt.Errorf("invalid key")
+	}
+	sum := sha256.Sum256(sshMarshalRSAPublic(s.key))
+
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:]), nil
+}
+
+// 转换为OpenSSH私钥格式(OPENSSH PRIVATE KEY)，不支持加密
+func (s *RSAPrivate) ToSSH(comment string) ([]byte, error) {
+	block, err := s.encodeSSH(comment)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(block), nil
+}
+
+func (s *RSAPrivate) ToSSHFile(path, comment string) error {
+	block, err := s.encodeSSH(comment)
+	if err != nil {
+		return err
+	}
+
+	folder := filepath.Dir(path)
+	err = os.MkdirAll(folder, 0777)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return pem.Encode(file, block)
+}
+
+// 从OpenSSH私钥格式(OPENSSH PRIVATE KEY)加载私钥，返回注释
+func (s *RSAPrivate) FromSSH(data []byte) (string, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != sshPrivateKeyPemType {
+		return "", fmt.Errorf("invalid openssh private key")
+	}
+	key, comment, err := parseOpenSSHPrivateKey(block.Bytes)
+	if err != nil {
+		return "", err
+	}
+	s.key = key
+
+	return comment, nil
+}
+
+func (s *RSAPrivate) FromSSHFile(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return s.FromSSH(data)
+}
+
+func (s *RSAPrivate) encodeSSH(comment string) (*pem.Block, error) {
+	if s.key == nil {
+		return nil, fmt.Errorf("invalid key")
+	}
+	if len(s.key.Primes) != 2 {
+		return nil, fmt.Errorf("openssh does not support multi-prime rsa key")
+	}
+	key := s.key
+	if key.Precomputed.Qinv == nil {
+		key.Precompute()
+	}
+
+	check := make([]byte, 4)
+	_, err := rand.Read(check)
+	if err != nil {
+		return nil, err
+	}
+
+	private := &sshWriter{}
+	private.Raw(check)
+	private.Raw(check)
+	private.String([]byte(sshKeyTypeRSA))
+	private.Mpint(key.N)
+	private.Mpint(big.NewInt(int64(key.E)))
+	private.Mpint(key.D)
+	private.Mpint(key.Precomputed.Qinv)
+	private.Mpint(key.Primes[0])
+	private.Mpint(key.Primes[1])
+	private.String([]byte(comment))
+	// 未加密时按8字节对齐，填充1, 2, 3...
+	for i := byte(1); private.Len()%8 != 0; i++ {
+		private.Raw([]byte{i})
+	}
+
+	w := &sshWriter{}
+	w.Raw([]byte(sshPrivateKeyMagic))
+	w.String([]byte("none"))
+	w.String([]byte("none"))
+	w.String(nil)
+	w.Uint32(1)
+	w.String(sshMarshalRSAPublic(&key.PublicKey))
+	w.String(private.Bytes())
+
+	return &pem.Block{
+		Type:  sshPrivateKeyPemType,
+		Bytes: w.Bytes(),
+	}, nil
+}
+
+func parseOpenSSHPrivateKey(data []byte) (*rsa.PrivateKey, string, error) {
+	if !bytes.HasPrefix(data, []byte(sshPrivateKeyMagic)) {
+		return nil, "", fmt.Errorf("invalid openssh private key: bad magic")
+	}
+	r := &sshReader{data: data[len(sshPrivateKeyMagic):]}
+	cipherName := string(r.String())
+	kdfName := string(r.String())
+	r.String() // kdf options
+	count := r.Uint32()
+	publicBlob := r.String()
+	private := r.String()
+	if r.err != nil {
+		return nil, "", fmt.Errorf("invalid openssh private key: %v", r.err)
+	}
+	if cipherName != "none" || kdfName != "none" {
+		return nil, "", fmt.Errorf("encrypted openssh private key is not supported: %s/%s", cipherName, kdfName)
+	}
+	if count != 1 {
+		return nil, "", fmt.Errorf("invalid openssh private key: %d keys", count)
+	}
+	public, err := sshParseRSAPublic(publicBlob)
+	if err != nil {
+		return nil, "", err
+	}
+
+	r = &sshReader{data: private}
+	check1 := r.Uint32()
+	check2 := r.Uint32()
+	keyType := string(r.String())
+	if r.err == nil && check1 != check2 {
+		return nil, "", fmt.Errorf("invalid openssh private key: check mismatch")
+	}
+	if r.err == nil && keyType != sshKeyTypeRSA {
+		return nil, "", fmt.Errorf("not a rsa private key: %s", keyType)
+	}
+	n := r.Mpint()
+	e := r.Mpint()
+	d := r.Mpint()
+	iqmp := r.Mpint()
+	p := r.Mpint()
+	q := r.Mpint()
+	comment := string(r.String())
+	if r.err != nil {
+		return nil, "", fmt.Errorf("invalid openssh private key: %v", r.err)
+	}
+	for i, b := range r.data {
+		if b != byte(i+1) {
+			return nil, "", fmt.Errorf("invalid openssh private key: bad padding")
+		}
+	}
+	if !e.IsInt64() || e.Int64() > int64(^uint32(0)>>1) {
+		return nil, "", fmt.Errorf("invalid openssh private key: exponent too large")
+	}
+
+	key := &rsa.PrivateKey{
+		PublicKey: rsa.PublicKey{N: n, E: int(e.Int64())},
+		D:         d,
+		Primes:    []*big.Int{p, q},
+	}
+	if !public.Equal(&key.PublicKey) {
+		return nil, "", fmt.Errorf("invalid openssh private key: public key mismatch")
+	}
+	err = key.Validate()
+	if err != nil {
+		return nil, "", err
+	}
+	key.Precompute()
+	// iqmp = q^-1 mod p
+	if key.Precomputed.Qinv.Cmp(iqmp) != 0 {
+		return nil, "", fmt.Errorf("invalid openssh private key: invalid iqmp")
+	}
+
+	return key, comment, nil
+}
+
+func sshMarshalRSAPublic(key *rsa.PublicKey) []byte {
+	w := &sshWriter{}
+	w.String([]byte(sshKeyTypeRSA))
+	w.Mpint(big.NewInt(int64(key.E)))
+	w.Mpint(key.N)
+
+	return w.Bytes()
+}
+
+func sshParseRSAPublic(blob []byte) (*rsa.PublicKey, error) {
+	r := &sshReader{data: blob}
+	keyType := string(r.String())
+	e := r.Mpint()
+	n := r.Mpint()
+	if r.err != nil {
+		return nil, fmt.Errorf("invalid ssh public key: %v", r.err)
+	}
+	if keyType != sshKeyTypeRSA {
+		return nil, fmt.Errorf("not a rsa public key: %s", keyType)
+	}
+	if len(r.data) != 0 {
+		return nil, fmt.Errorf("invalid ssh public key: trailing data")
+	}
+	if !e.IsInt64() || e.Int64() > int64(^uint32(0)>>1) || e.Int64() < 3 {
+		return nil, fmt.Errorf("invalid ssh public key: bad exponent")
+	}
+
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+// SSH线格式编码，参考：RFC 4251 5
+type sshWriter struct {
+	buf bytes.Buffer
+}
+
+func (s *sshWriter) Bytes() []byte {
+	return s.buf.Bytes()
+}
+
+func (s *sshWriter) Len() int {
+	return s.buf.Len()
+}
+
+func (s *sshWriter) Raw(data []byte) {
+	s.buf.Write(data)
+}
+
+func (s *sshWriter) Uint32(v uint32) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	s.buf.Write(b[:])
+}
+
+func (s *sshWriter) String(data []byte) {
+	s.Uint32(uint32(len(data)))
+	s.buf.Write(data)
+}
+
+// 非负整数，最高位为1时前补0
+func (s *sshWriter) Mpint(v *big.Int) {
+	data := v.Bytes()
+	if len(data) > 0 && data[0]&0x80 != 0 {
+		data = append([]byte{0}, data...)
+	}
+	s.String(data)
+}
+
+// SSH线格式解码，出错后后续读取均返回空值
+type sshReader struct {
+	data []byte
+	err  error
+}
+
+func (s *sshReader) Uint32() uint32 {
+	if s.err != nil {
+		return 0
+	}
+	if len(s.data) < 4 {
+		s.err = fmt.Errorf("unexpected end of data")
+		return 0
+	}
+	v := binary.BigEndian.Uint32(s.data)
+	s.data = s.data[4:]
+
+	return v
+}
+
+func (s *sshReader) String() []byte {
+	length := s.Uint32()
+	if s.err != nil {
+		return nil
+	}
+	if uint32(len(s.data)) < length {
+		s.err = fmt.Errorf("unexpected end of data")
+		return nil
+	}
+	v := s.data[:length]
+	s.data = s.data[length:]
+
+	return v
+}
+
+func (s *sshReader) Mpint() *big.Int {
+	data := s.String()
+	if s.err != nil {
+		return new(big.Int)
+	}
+	if len(data) > 0 && data[0]&0x80 != 0 {
+		s.err = fmt.Errorf("negative mpint")
+		return new(big.Int)
+	}
+
+	return new(big.Int).SetBytes(data)
+}
@@ -0,0 +1,331 @@
+package certificate
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// 远程签名服务接口，私钥保存在外部(如KMS、HSM)，仅提供公钥及签名操作
+type RemoteSigner interface {
+	// 获取签名密钥的公钥
+	PublicKey() (crypto.PublicKey, error)
+
+	// 对摘要签名，opts为crypto.Hash或*rsa.PSSOptions
+	// Ed25519密钥时digest为原始消息，opts为crypto.Hash(0)
+	SignDigest(digest []byte, opts crypto.SignerOpts) ([]byte, error)
+}
+
+// 将远程签名服务适配为crypto.Signer，创建时获取并缓存公钥
+// 可通过NewSignerKey包装后用于Crt.Create、CrtCrl.ToMemory、CrtPfx.ToMemory等，或使用SignerKey.Sign对数据签名
+func NewRemoteCryptoSigner(signer RemoteSigner) (crypto.Signer, error) {
+	if signer == nil {
+		return nil, fmt.Errorf("invalid remote signer: nil")
+	}
+	public, err := signer.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	switch public.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey:
+	default:
+		return nil, fmt.Errorf("not support public key: %T", public)
+	}
+
+	return &remoteCryptoSigner{signer: signer, public: public}, nil
+}
+
+type remoteCryptoSigner struct {
+	signer RemoteSigner
+	public crypto.PublicKey
+}
+
+func (s *remoteCryptoSigner) Public() crypto.PublicKey {
+	return s.public
+}
+
+func (s *remoteCryptoSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return s.signer.SignDigest(digest, opts)
+}
+
+// HTTP签名协议
+// 获取公钥：GET {Url}/keys/{keyId}，响应：{"keyId": "", "publicKey": "公钥(PKIX, DER)的base64"}
+// 签名：POST {Url}/keys/{keyId}/sign，请求：{"digest": "摘要的base64", "hash": "SHA-256", "pss": false, "saltLength": 0}，响应：{"signature": "签名的base64"}
+// 失败时返回非200状态码及{"error": "错误信息"}，设置令牌时使用Authorization: Bearer {Token}认证
+type remoteKeyInfo struct {
+	KeyId     string `json:"keyId"`
+	PublicKey string `json:"publicKey"`
+}
+
+type remoteSignRequest struct {
+	Digest     string `json:"digest"`
+	Hash       string `json:"hash"`
+	Pss        bool   `json:"pss"`
+	SaltLength int    `json:"saltLength"`
+}
+
+type remoteSignResponse struct {
+	Signature string `json:"signature"`
+}
+
+type remoteError struct {
+	Error string `json:"error"`
+}
+
+var remoteHashes = []crypto.Hash{
+	crypto.MD5, crypto.SHA1, crypto.SHA224, crypto.SHA256, crypto.SHA384, crypto.SHA512,
+}
+
+func remoteHashName(h crypto.Hash) string {
+	if h == 0 {
+		return ""
+	}
+
+	return h.String()
+}
+
+func remoteHashFromName(name string) (crypto.Hash, error) {
+	if name == "" {
+		return 0, nil
+	}
+	for _, h := range remoteHashes {
+		if h.String() == name {
+			return h, nil
+		}
+	}
+
+	return 0, fmt.Errorf("not support hash: %s", name)
+}
+
+// HTTP签名协议客户端，实现RemoteSigner接口
+type HttpSigner struct {
+	Url    string       // 服务地址，如：http://127.0.0.1:8080/signer
+	KeyId  string       // 密钥标识
+	Token  string       // 访问令牌，可为空
+	Client *http.Client // 为空时使用默认客户端(超时时间30秒)
+}
+
+func (s *HttpSigner) PublicKey() (crypto.PublicKey, error) {
+	info := &remoteKeyInfo{}
+	err := s.do(http.MethodGet, "", nil, info)
+	if err != nil {
+		return nil, err
+	}
+	data, err := base64.StdEncoding.DecodeString(info.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %v", err)
+	}
+
+	return x509.ParsePKIXPublicKey(data)
+}
+
+func (s *HttpSigner) SignDigest(digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	request := &remoteSignRequest{
+		Digest: base64.StdEncoding.EncodeToString(digest),
+	}
+	if opts != nil {
+		request.Hash = remoteHashName(opts.HashFunc())
+	}
+	if pss, ok := opts.(*rsa.PSSOptions); ok {
+		request.Pss = true
+		request.SaltLength = pss.SaltLength
+	}
+
+	response := &remoteSignResponse{}
+	err := s.do(http.MethodPost, "/sign", request, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return base64.StdEncoding.DecodeString(response.Signature)
+}
+
+func (s *HttpSigner) do(method, action string, request, response interface{}) error {
+	if len(s.KeyId) < 1 {
+		return fmt.Errorf("invalid key id: empty")
+	}
+	uri := strings.TrimRight(s.Url, "/") + "/keys/" + url.PathEscape(s.KeyId) + action
+
+	var body io.Reader
+	if request != nil {
+		data, err := json.Marshal(request)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, uri, body)
+	if err != nil {
+		return err
+	}
+	if request != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if len(s.Token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = defaultHttpClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := readHttpBody(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		result := &remoteError{}
+		if json.Unmarshal(data, result) == nil && len(result.Error) > 0 {
+			return fmt.Errorf("remote signer: %s", result.Error)
+		}
+		return fmt.Errorf("remote signer: %s", resp.Status)
+	}
+
+	return json.Unmarshal(data, response)
+}
+
+var remoteKeyIdPattern = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9_.-]*$`)
+
+// HTTP签名协议的本地参考服务，用于测试及开发
+// 私钥以文件形式保存在Folder中，文件名为{keyId}.key，支持Loader可识别的格式
+type SignerServer struct {
+	Folder   string // 私钥文件目录
+	Password string // 私钥文件密码
+	Token    string // 访问令牌，为空时不认证
+}
+
+func (s *SignerServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if len(s.Token) > 0 {
+		authorization := r.Header.Get("Authorization")
+		if !strings.HasPrefix(authorization, "Bearer ") ||
+			subtle.ConstantTimeCompare([]byte(authorization[len("Bearer "):]), []byte(s.Token)) != 1 {
+			s.error(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+	}
+
+	index := strings.LastIndex(r.URL.Path, "/keys/")
+	if index < 0 {
+		s.error(w, http.StatusNotFound, "not found")
+		return
+	}
+	keyId := r.URL.Path[index+len("/keys/"):]
+	action := ""
+	if strings.HasSuffix(keyId, "/sign") {
+		keyId = strings.TrimSuffix(keyId, "/sign")
+		action = "sign"
+	}
+	if !remoteKeyIdPattern.MatchString(keyId) {
+		s.error(w, http.StatusNotFound, "invalid key id")
+		return
+	}
+
+	signer, err := s.loadKey(keyId)
+	if err != nil {
+		s.error(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if action == "" && r.Method == http.MethodGet {
+		data, err := x509.MarshalPKIXPublicKey(signer.Public())
+		if err != nil {
+			s.error(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.write(w, &remoteKeyInfo{
+			KeyId:     keyId,
+			PublicKey: base64.StdEncoding.EncodeToString(data),
+		})
+	} else if action == "sign" && r.Method == http.MethodPost {
+		signature, err := s.sign(signer, r)
+		if err != nil {
+			s.error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.write(w, &remoteSignResponse{
+			Signature: base64.StdEncoding.EncodeToString(signature),
+		})
+	} else {
+		s.error(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (s *SignerServer) loadKey(keyId string) (crypto.Signer, error) {
+	loader := &Loader{Password: s.Password}
+	result, err := loader.FromFile(filepath.Join(s.Folder, keyId+".key"))
+	if err != nil {
+		return nil, fmt.Errorf("key '%s' not found", keyId)
+	}
+
+	var key PrivateKey
+	if len(result.PrivateKeys) > 0 {
+		key = result.PrivateKeys[0]
+	} else if len(result.ECDSAPrivateKeys) > 0 {
+		key = result.ECDSAPrivateKeys[0]
+	} else if len(result.Ed25519PrivateKeys) > 0 {
+		key = result.Ed25519PrivateKeys[0]
+	} else {
+		return nil, fmt.Errorf("key '%s' not found", keyId)
+	}
+
+	return key.CryptoSigner()
+}
+
+func (s *SignerServer) sign(signer crypto.Signer, r *http.Request) ([]byte, error) {
+	request := &remoteSignRequest{}
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %v", err)
+	}
+	digest, err := base64.StdEncoding.DecodeString(request.Digest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid digest: %v", err)
+	}
+	h, err := remoteHashFromName(request.Hash)
+	if err != nil {
+		return nil, err
+	}
+	if h != 0 && len(digest) != h.Size() {
+		return nil, fmt.Errorf("invalid digest length: %d", len(digest))
+	}
+	if _, ok := signer.Public().(ed25519.PublicKey); !ok && h == 0 {
+		return nil, fmt.Errorf("invalid hash: empty")
+	}
+
+	var opts crypto.SignerOpts = h
+	if request.Pss {
+		opts = &rsa.PSSOptions{SaltLength: request.SaltLength, Hash: h}
+	}
+
+	return signer.Sign(rand.Reader, digest, opts)
+}
+
+func (s *SignerServer) write(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func (s *SignerServer) error(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(&remoteError{Error: message})
+}
@@ -0,0 +1,535 @@
+package encoding
+
+import (
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ASN.1 DER/BER结构解析，输出类似`openssl asn1parse`
+const (
+	Asn1ClassUniversal       = 0
+	Asn1ClassApplication     = 1
+	Asn1ClassContextSpecific = 2
+	Asn1ClassPrivate         = 3
+)
+
+// 常见OID名称
+var Asn1OidNames = map[string]string{
+	"1.2.840.113549.1.1.1":       "rsaEncryption",
+	"1.2.840.113549.1.1.4":       "md5WithRSAEncryption",
+	"1.2.840.113549.1.1.5":       "sha1WithRSAEncryption",
+	"1.2.840.113549.1.1.7":       "rsaesOaep",
+	"1.2.840.113549.1.1.8":       "mgf1",
+	"1.2.840.113549.1.1.10":      "rsassaPss",
+	"1.2.840.113549.1.1.11":      "sha256WithRSAEncryption",
+	"1.2.840.113549.1.1.12":      "sha384WithRSAEncryption",
+	"1.2.840.113549.1.1.13":      "sha512WithRSAEncryption",
+	"1.2.840.10045.2.1":          "id-ecPublicKey",
+	"1.2.840.10045.3.1.7":        "prime256v1",
+	"1.3.132.0.34":               "secp384r1",
+	"1.3.132.0.35":               "secp521r1",
+	"1.2.840.10045.4.3.2":        "ecdsa-with-SHA256",
+	"1.2.840.10045.4.3.3":        "ecdsa-with-SHA384",
+	"1.2.840.10045.4.3.4":        "ecdsa-with-SHA512",
+	"1.3.101.112":                "ED25519",
+	"1.3.14.3.2.26":              "sha1",
+	"2.16.840.1.101.3.4.2.1":     "sha256",
+	"2.16.840.1.101.3.4.2.2":     "sha384",
+	"2.16.840.1.101.3.4.2.3":     "sha512",
+	"2.16.840.1.101.3.4.1.2":     "aes-128-cbc",
+	"2.16.840.1.101.3.4.1.22":    "aes-192-cbc",
+	"2.16.840.1.101.3.4.1.42":    "aes-256-cbc",
+	"1.2.840.113549.2.7":         "hmacWithSHA1",
+	"1.2.840.113549.2.9":         "hmacWithSHA256",
+	"1.2.840.113549.2.11":        "hmacWithSHA512",
+	"1.2.840.113549.1.5.12":      "PBKDF2",
+	"1.2.840.113549.1.5.13":      "PBES2",
+	"1.2.840.113549.1.7.1":       "pkcs7-data",
+	"1.2.840.113549.1.7.2":       "pkcs7-signedData",
+	"1.2.840.113549.1.7.6":       "pkcs7-encryptedData",
+	"1.2.840.113549.1.9.1":       "emailAddress",
+	"1.2.840.113549.1.9.7":       "challengePassword",
+	"1.2.840.113549.1.9.14":      "extensionRequest",
+	"1.2.840.113549.1.9.20":      "friendlyName",
+	"1.2.840.113549.1.9.21":      "localKeyID",
+	"1.2.840.113549.1.9.22.1":    "x509Certificate",
+	"1.2.840.113549.1.12.1.3":    "pbeWithSHA1And3-KeyTripleDES-CBC",
+	"1.2.840.113549.1.12.1.6":    "pbeWithSHA1And40BitRC2-CBC",
+	"1.2.840.113549.1.12.10.1.1": "keyBag",
+	"1.2.840.113549.1.12.10.1.2": "pkcs8ShroudedKeyBag",
+	"1.2.840.113549.1.12.10.1.3": "certBag",
+	"2.5.4.3":                    "commonName",
+	"2.5.4.5":                    "serialNumber",
+	"2.5.4.6":                    "countryName",
+	"2.5.4.7":                    "localityName",
+	"2.5.4.8":                    "stateOrProvinceName",
+	"2.5.4.9":                    "streetAddress",
+	"2.5.4.10":                   "organizationName",
+	"2.5.4.11":                   "organizationalUnitName",
+	"2.5.29.14":                  "X509v3 Subject Key Identifier",
+	"2.5.29.15":                  "X509v3 Key Usage",
+	"2.5.29.17":                  "X509v3 Subject Alternative Name",
+	"2.5.29.19":                  "X509v3 Basic Constraints",
+	"2.5.29.20":                  "X509v3 CRL Number",
+	"2.5.29.21":                  "X509v3 CRL Reason Code",
+	"2.5.29.31":                  "X509v3 CRL Distribution Points",
+	"2.5.29.32":                  "X509v3 Certificate Policies",
+	"2.5.29.35":                  "X509v3 Authority Key Identifier",
+	"2.5.29.37":                  "X509v3 Extended Key Usage",
+	"1.3.6.1.5.5.7.1.1":          "Authority Information Access",
+	"1.3.6.1.5.5.7.3.1":          "TLS Web Server Authentication",
+	"1.3.6.1.5.5.7.3.2":          "TLS Web Client Authentication",
+	"1.3.6.1.5.5.7.48.1":         "OCSP",
+	"1.3.6.1.5.5.7.48.2":         "CA Issuers",
+}
+
+var asn1TagNames = map[int]string{
+	asn1.TagBoolean:         "BOOLEAN",
+	asn1.TagInteger:         "INTEGER",
+	asn1.TagBitString:       "BIT STRING",
+	asn1.TagOctetString:     "OCTET STRING",
+	asn1.TagNull:            "NULL",
+	asn1.TagOID:             "OBJECT",
+	asn1.TagEnum:            "ENUMERATED",
+	asn1.TagUTF8String:      "UTF8STRING",
+	asn1.TagSequence:        "SEQUENCE",
+	asn1.TagSet:             "SET",
+	asn1.TagNumericString:   "NUMERICSTRING",
+	asn1.TagPrintableString: "PRINTABLESTRING",
+	asn1.TagT61String:       "T61STRING",
+	asn1.TagIA5String:       "IA5STRING",
+	asn1.TagUTCTime:         "UTCTIME",
+	asn1.TagGeneralizedTime: "GENERALIZEDTIME",
+	asn1.TagGeneralString:   "GENERALSTRING",
+	asn1.TagBMPString:       "BMPSTRING",
+	28:                      "UNIVERSALSTRING",
+}
+
+type Asn1Node struct {
+	Offset       int         `json:"offset" note:"偏移"`
+	Depth        int         `json:"depth" note:"深度"`
+	HeaderLength int         `json:"headerLength" note:"头部长度"`
+	Length       int         `json:"length" note:"内容长度，不定长编码时为-1"`
+	Class        int         `json:"class" note:"类别"`
+	Tag          int         `json:"tag" note:"标签"`
+	Constructed  bool        `json:"constructed" note:"是否为结构类型"`
+	Encapsulated bool        `json:"encapsulated" note:"是否为OCTET STRING/BIT STRING中封装的结构"`
+	Value        string      `json:"value" note:"值"`
+	Bytes        []byte      `json:"-"`
+	Children     []*Asn1Node `json:"children,omitempty" note:"子节点"`
+}
+
+// 标签名称，如：SEQUENCE、cont [ 0 ]
+func (s *Asn1Node) TagName() string {
+	switch s.Class {
+	case Asn1ClassUniversal:
+		if name, ok := asn1TagNames[s.Tag]; ok {
+			return name
+		}
+		return fmt.Sprintf("univ [ %d ]", s.Tag)
+	case Asn1ClassApplication:
+		return fmt.Sprintf("appl [ %d ]", s.Tag)
+	case Asn1ClassContextSpecific:
+		return fmt.Sprintf("cont [ %d ]", s.Tag)
+	}
+
+	return fmt.Sprintf("priv [ %d ]", s.Tag)
+}
+
+type Asn1Parser struct {
+	Encapsulated bool // 是否尝试解析OCTET STRING/BIT STRING中封装的结构
+	MaxDepth     int  // 最大解析深度，默认64
+}
+
+func (s *Asn1Parser) Parse(data []byte) ([]*Asn1Node, error) {
+	nodes, _, err := s.parse(data, 0, 0, false)
+	return nodes, err
+}
+
+// BER转换为DER：不定长编码转换为定长编码，分段的OCTET STRING、BIT STRING合并为单个
+// 不对SET OF的元素排序，不解析OCTET STRING/BIT STRING中封装的结构
+func (s *Asn1Parser) ToDer(data []byte) ([]byte, error) {
+	parser := &Asn1Parser{MaxDepth: s.MaxDepth}
+	nodes, err := parser.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.encodeDer(nodes)
+}
+
+func (s *Asn1Parser) encodeDer(nodes []*Asn1Node) ([]byte, error) {
+	der := make([]byte, 0)
+	for _, node := range nodes {
+		value := asn1.RawValue{
+			Class:      node.Class,
+			Tag:        node.Tag,
+			IsCompound: node.Constructed,
+			Bytes:      node.Bytes,
+		}
+		if node.Constructed {
+			if node.Class == Asn1ClassUniversal && node.Tag == asn1.TagOctetString {
+				value.IsCompound = false
+				value.Bytes = s.octets(node)
+			} else if node.Class == Asn1ClassUniversal && node.Tag == asn1.TagBitString {
+				bits, err := s.bits(node)
+				if err != nil {
+					return nil, err
+				}
+				value.IsCompound = false
+				value.Bytes = bits
+			} else {
+				content, err := s.encodeDer(node.Children)
+				if err != nil {
+					return nil, err
+				}
+				value.Bytes = content
+			}
+		}
+		item, err := asn1.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		der = append(der, item...)
+	}
+
+	return der, nil
+}
+
+func (s *Asn1Parser) octets(node *Asn1Node) []byte {
+	if !node.Constructed {
+		return node.Bytes
+	}
+	data := make([]byte, 0)
+	for _, child := range node.Children {
+		data = append(data, s.octets(child)...)
+	}
+
+	return data
+}
+
+// 合并分段的BIT STRING，除最后一段外，各段的未使用位数须为0
+func (s *Asn1Parser) bits(node *Asn1Node) ([]byte, error) {
+	unused := byte(0)
+	data := make([]byte, 0)
+	segments := make([]*Asn1Node, 0)
+	s.segments(node, &segments)
+	for i, segment := range segments {
+		if len(segment.Bytes) < 1 {
+			return nil, fmt.Errorf("asn1: empty bit string segment at offset %d", segment.Offset)
+		}
+		if segment.Bytes[0] != 0 && i != len(segments)-1 {
+			return nil, fmt.Errorf("asn1: invalid bit string segment at offset %d", segment.Offset)
+		}
+		unused = segment.Bytes[0]
+		data = append(data, segment.Bytes[1:]...)
+	}
+	if unused > 7 || (unused > 0 && len(data) < 1) {
+		return nil, fmt.Errorf("asn1: invalid bit string at offset %d", node.Offset)
+	}
+
+	return append([]byte{unused}, data...), nil
+}
+
+func (s *Asn1Parser) segments(node *Asn1Node, segments *[]*Asn1Node) {
+	if !node.Constructed {
+		*segments = append(*segments, node)
+		return
+	}
+	for _, child := range node.Children {
+		s.segments(child, segments)
+	}
+}
+
+// 以`openssl asn1parse -i`格式输出
+func (s *Asn1Parser) Dump(w io.Writer, data []byte) error {
+	nodes, err := s.Parse(data)
+	if err != nil {
+		return err
+	}
+
+	return s.dump(w, nodes)
+}
+
+func (s *Asn1Parser) DumpToString(data []byte) (string, error) {
+	sb := &strings.Builder{}
+	err := s.Dump(sb, data)
+	if err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
+
+func (s *Asn1Parser) dump(w io.Writer, nodes []*Asn1Node) error {
+	for _, node := range nodes {
+		kind := "prim"
+		if node.Constructed {
+			kind = "cons"
+		}
+		length := fmt.Sprintf("%4d", node.Length)
+		if node.Length < 0 {
+			length = "inf "
+		}
+		line := fmt.Sprintf("%5d:d=%-2d hl=%d l=%s %s: %s%s",
+			node.Offset, node.Depth, node.HeaderLength, length, kind,
+			strings.Repeat(" ", node.Depth), node.TagName())
+		if len(node.Value) > 0 {
+			line = fmt.Sprintf("%-60s :%s", line, node.Value)
+		}
+		_, err := fmt.Fprintln(w, line)
+		if err != nil {
+			return err
+		}
+
+		err = s.dump(w, node.Children)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 解析节点序列，返回已解析的字节数(含不定长编码的结束标记)
+// indefinite为true时表示不定长编码的内容，遇到结束标记时停止，其它情况下结束标记视为错误
+func (s *Asn1Parser) parse(data []byte, offset, depth int, indefinite bool) ([]*Asn1Node, int, error) {
+	maxDepth := s.MaxDepth
+	if maxDepth < 1 {
+		maxDepth = 64
+	}
+	if depth > maxDepth {
+		return nil, 0, fmt.Errorf("asn1: max depth %d exceeded at offset %d", maxDepth, offset)
+	}
+
+	nodes := make([]*Asn1Node, 0)
+	pos := 0
+	for pos < len(data) {
+		node, size, err := s.parseNode(data[pos:], offset+pos, depth)
+		if err != nil {
+			return nodes, pos, err
+		}
+		if isAsn1EndOfContents(node) {
+			if !indefinite {
+				return nodes, pos, fmt.Errorf("asn1: unexpected end-of-contents at offset %d", offset+pos)
+			}
+			nodes = append(nodes, node)
+			pos += size
+			break
+		}
+		nodes = append(nodes, node)
+		pos += size
+	}
+
+	return nodes, pos, nil
+}
+
+// 解析单个节点，返回节点及其占用的字节数
+func (s *Asn1Parser) parseNode(data []byte, offset, depth int) (*Asn1Node, int, error) {
+	if len(data) < 2 {
+		return nil, 0, fmt.Errorf("asn1: truncated header at offset %d", offset)
+	}
+
+	node := &Asn1Node{
+		Offset:      offset,
+		Depth:       depth,
+		Class:       int(data[0] >> 6),
+		Constructed: data[0]&0x20 != 0,
+		Tag:         int(data[0] & 0x1f),
+	}
+	pos := 1
+	if node.Tag == 0x1f {
+		// 高标签号
+		node.Tag = 0
+		for {
+			if pos >= len(data) {
+				return nil, 0, fmt.Errorf("asn1: truncated tag at offset %d", offset)
+			}
+			b := data[pos]
+			pos++
+			node.Tag = node.Tag<<7 | int(b&0x7f)
+			if b&0x80 == 0 {
+				break
+			}
+			if node.Tag > 1<<24 {
+				return nil, 0, fmt.Errorf("asn1: tag too large at offset %d", offset)
+			}
+		}
+	}
+
+	if pos >= len(data) {
+		return nil, 0, fmt.Errorf("asn1: truncated length at offset %d", offset)
+	}
+	b := data[pos]
+	pos++
+	length := 0
+	if b == 0x80 {
+		length = -1
+	} else if b&0x80 == 0 {
+		length = int(b)
+	} else {
+		count := int(b & 0x7f)
+		// 最多3个字节，避免32位平台上溢出
+		if count > 3 || pos+count > len(data) {
+			return nil, 0, fmt.Errorf("asn1: invalid length at offset %d", offset)
+		}
+		for i := 0; i < count; i++ {
+			length = length<<8 | int(data[pos])
+			pos++
+		}
+	}
+	node.HeaderLength = pos
+	node.Length = length
+
+	if length < 0 {
+		if !node.Constructed {
+			return nil, 0, fmt.Errorf("asn1: indefinite length for primitive at offset %d", offset)
+		}
+		children, size, err := s.parse(data[pos:], offset+pos, depth+1, true)
+		node.Children = children
+		if err != nil {
+			return node, pos + size, err
+		}
+		// 去除结束标记，size已包含子节点及自身的结束标记
+		if len(children) < 1 || !isAsn1EndOfContents(children[len(children)-1]) {
+			return node, pos + size, fmt.Errorf("asn1: missing end-of-contents at offset %d", offset)
+		}
+		node.Children = children[:len(children)-1]
+		node.Bytes = data[pos : pos+size-2]
+		return node, pos + size, nil
+	}
+
+	if pos+length > len(data) {
+		return nil, 0, fmt.Errorf("asn1: content length %d exceeds data at offset %d", length, offset)
+	}
+	node.Bytes = data[pos : pos+length]
+
+	if node.Constructed {
+		children, _, err := s.parse(node.Bytes, offset+pos, depth+1, false)
+		node.Children = children
+		return node, pos + length, err
+	}
+
+	node.Value = s.value(node)
+	if s.Encapsulated && node.Class == Asn1ClassUniversal {
+		s.parseEncapsulated(node, offset+pos, depth)
+	}
+
+	return node, pos + length, nil
+}
+
+// 尝试解析OCTET STRING/BIT STRING中封装的结构，失败时按普通数据处理
+func (s *Asn1Parser) parseEncapsulated(node *Asn1Node, offset, depth int) {
+	content := node.Bytes
+	if node.Tag == asn1.TagBitString {
+		if len(content) < 2 || content[0] != 0 {
+			return
+		}
+		content = content[1:]
+		offset++
+	} else if node.Tag != asn1.TagOctetString {
+		return
+	}
+	// 仅处理以SEQUENCE或SET开头的内容
+	if len(content) < 2 || (content[0] != 0x30 && content[0] != 0x31) {
+		return
+	}
+
+	children, _, err := s.parse(content, offset, depth+1, false)
+	if err != nil || len(children) < 1 {
+		return
+	}
+	for _, c := range children {
+		c.Encapsulated = true
+	}
+	node.Children = children
+	node.Value = ""
+}
+
+func (s *Asn1Parser) value(node *Asn1Node) string {
+	if node.Class != Asn1ClassUniversal {
+		return s.hexValue(node.Bytes)
+	}
+
+	data := node.Bytes
+	switch node.Tag {
+	case asn1.TagBoolean:
+		if len(data) == 1 && data[0] != 0 {
+			return "255"
+		}
+		return "0"
+	case asn1.TagInteger, asn1.TagEnum:
+		v := new(big.Int).SetBytes(data)
+		if len(data) > 0 && data[0]&0x80 != 0 {
+			v.Sub(v, new(big.Int).Lsh(big.NewInt(1), uint(len(data)*8)))
+		}
+		if len(data) > 16 {
+			return strings.ToUpper(hex.EncodeToString(data))
+		}
+		return v.String()
+	case asn1.TagOID:
+		var oid asn1.ObjectIdentifier
+		full := append([]byte{asn1.TagOID, byte(len(data))}, data...)
+		if len(data) > 127 {
+			return s.hexValue(data)
+		}
+		_, err := asn1.Unmarshal(full, &oid)
+		if err != nil {
+			return s.hexValue(data)
+		}
+		if name, ok := Asn1OidNames[oid.String()]; ok {
+			return name + " (" + oid.String() + ")"
+		}
+		return oid.String()
+	case asn1.TagUTF8String, asn1.TagPrintableString, asn1.TagIA5String, asn1.TagNumericString,
+		asn1.TagT61String, asn1.TagGeneralString:
+		if utf8.Valid(data) {
+			return string(data)
+		}
+		return s.hexValue(data)
+	case asn1.TagBMPString:
+		if len(data)%2 != 0 {
+			return s.hexValue(data)
+		}
+		u := make([]uint16, len(data)/2)
+		for i := range u {
+			u[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+		}
+		return string(utf16.Decode(u))
+	case asn1.TagUTCTime:
+		t, err := time.Parse("060102150405Z0700", string(data))
+		if err != nil {
+			return string(data)
+		}
+		return t.UTC().Format("2006-01-02 15:04:05 MST")
+	case asn1.TagGeneralizedTime:
+		t, err := time.Parse("20060102150405Z0700", string(data))
+		if err != nil {
+			return string(data)
+		}
+		return t.UTC().Format("2006-01-02 15:04:05 MST")
+	case asn1.TagNull:
+		return ""
+	}
+
+	return s.hexValue(data)
+}
+
+func (s *Asn1Parser) hexValue(data []byte) string {
+	if len(data) < 1 {
+		return ""
+	}
+
+	return "[HEX DUMP]:" + strings.ToUpper(hex.EncodeToString(data))
+}
+
+func isAsn1EndOfContents(node *Asn1Node) bool {
+	return node.Class == Asn1ClassUniversal && node.Tag == 0 && node.Length == 0 && !node.Constructed
+}
@@ -0,0 +1,37 @@
+package encoding
+
+import (
+	"encoding/base32"
+	"strings"
+)
+
+const (
+	crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+)
+
+var (
+	crockfordEncoding = base32.NewEncoding(crockfordAlphabet).WithPadding(base32.NoPadding)
+	crockfordReplacer = strings.NewReplacer("-", "", "I", "1", "L", "1", "O", "0")
+)
+
+type Base32 struct {
+	Crockford bool // 是否使用Crockford字母表(无补齐)，默认为标准编码(RFC 4648 6)
+}
+
+func (s *Base32) EncodeToString(val []byte) string {
+	if s.Crockford {
+		return crockfordEncoding.EncodeToString(val)
+	}
+
+	return base32.StdEncoding.EncodeToString(val)
+}
+
+// Crockford解码时不区分大小写，忽略'-'，并将I、L视为1，O视为0
+func (s *Base32) DecodeFromString(val string) ([]byte, error) {
+	if s.Crockford {
+		val = crockfordReplacer.Replace(strings.ToUpper(val))
+		return crockfordEncoding.DecodeString(val)
+	}
+
+	return base32.StdEncoding.DecodeString(val)
+}
@@ -0,0 +1,135 @@
+package encoding
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+)
+
+const (
+	base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+)
+
+var base58Indexes = newBase58Indexes()
+
+// Base58编码(比特币字母表)
+type Base58 struct {
+	Check bool // 是否附加4字节校验值(Base58Check)：sha256(sha256(data))的前4字节
+}
+
+func (s *Base58) EncodeToString(val []byte) string {
+	if s.Check {
+		val = append(append(make([]byte, 0, len(val)+4), val...), base58Checksum(val)...)
+	}
+
+	return ToBase58String(val)
+}
+
+func (s *Base58) DecodeFromString(val string) ([]byte, error) {
+	data, err := FromBase58String(val)
+	if err != nil {
+		return nil, err
+	}
+	if !s.Check {
+		return data, nil
+	}
+
+	if len(data) < 4 {
+		return nil, fmt.Errorf("invalid base58check: too short")
+	}
+	payload := data[:len(data)-4]
+	if !bytes.Equal(base58Checksum(payload), data[len(data)-4:]) {
+		return nil, fmt.Errorf("invalid base58check: checksum mismatch")
+	}
+
+	return payload, nil
+}
+
+func ToBase58String(val []byte) string {
+	zeros := 0
+	for zeros < len(val) && val[zeros] == 0 {
+		zeros++
+	}
+
+	// log(256)/log(58) ≈ 1.37
+	size := (len(val)-zeros)*138/100 + 1
+	buf := make([]byte, size)
+	high := size - 1
+	for _, b := range val[zeros:] {
+		carry := int(b)
+		i := size - 1
+		for ; i > high || carry != 0; i-- {
+			carry += 256 * int(buf[i])
+			buf[i] = byte(carry % 58)
+			carry /= 58
+		}
+		high = i
+	}
+
+	start := 0
+	for start < size && buf[start] == 0 {
+		start++
+	}
+	out := make([]byte, zeros, zeros+size-start)
+	for i := range out {
+		out[i] = base58Alphabet[0]
+	}
+	for _, b := range buf[start:] {
+		out = append(out, base58Alphabet[b])
+	}
+
+	return string(out)
+}
+
+func FromBase58String(val string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(val) && val[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+
+	// log(58)/log(256) ≈ 0.733
+	size := (len(val)-zeros)*733/1000 + 1
+	buf := make([]byte, size)
+	high := size - 1
+	for pos := zeros; pos < len(val); pos++ {
+		index := base58Indexes[val[pos]]
+		if index < 0 {
+			return nil, fmt.Errorf("invalid base58 character '%c' at %d", val[pos], pos)
+		}
+		carry := int(index)
+		i := size - 1
+		for ; i > high || carry != 0; i-- {
+			carry += 58 * int(buf[i])
+			buf[i] = byte(carry % 256)
+			carry /= 256
+		}
+		high = i
+	}
+
+	start := 0
+	for start < size && buf[start] == 0 {
+		start++
+	}
+	out := make([]byte, zeros, zeros+size-start)
+
+	return append(out, buf[start:]...), nil
+}
+
+func base58Checksum(val []byte) []byte {
+	first := sha256.Sum256(val)
+	second := sha256.Sum256(first[:])
+
+	return second[:4]
+}
+
+func newBase58Indexes() [256]int8 {
+	indexes := [256]int8{}
+	for i := range indexes {
+		indexes[i] = -1
+	}
+	for i := 0; i < len(base58Alphabet); i++ {
+		indexes[base58Alphabet[i]] = int8(i)
+	}
+
+	return indexes
+}
@@ -1,18 +1,80 @@
 package encoding
 
-import "encoding/base64"
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	Base64Std    = 0 // 标准编码(RFC 4648 4)，默认
+	Base64Url    = 1 // URL安全编码(RFC 4648 5)
+	Base64RawStd = 2 // 不带补齐的标准编码
+	Base64RawUrl = 3 // 不带补齐的URL安全编码(JWT)
+	Base64Mime   = 4 // MIME编码(RFC 2045)，每76个字符以CRLF换行
+)
+
+const (
+	mimeLineLength = 76
+)
 
 type Base64 struct {
+	Variant int // Base64Std(default), Base64Url, Base64RawStd, Base64RawUrl or Base64Mime
 }
 
 func (s *Base64) EncodeToString(val []byte) string {
+	switch s.Variant {
+	case Base64Url:
+		return ToBase64UrlString(val)
+	case Base64RawStd:
+		return base64.RawStdEncoding.EncodeToString(val)
+	case Base64RawUrl:
+		return ToBase64RawUrlString(val)
+	case Base64Mime:
+		return ToBase64MimeString(val)
+	}
+
 	return ToBase64String(val)
 }
 
 func (s *Base64) DecodeFromString(val string) ([]byte, error) {
+	switch s.Variant {
+	case Base64Url:
+		return FromBase64UrlString(val)
+	case Base64RawStd:
+		return base64.RawStdEncoding.DecodeString(val)
+	case Base64RawUrl:
+		return FromBase64RawUrlString(val)
+	case Base64Mime:
+		return base64.StdEncoding.DecodeString(stripWhitespace(val))
+	}
+
 	return FromBase64String(val)
 }
 
+// 创建流式编码器，写入的数据编码后写入w，结束时须调用Close输出剩余数据
+func (s *Base64) NewEncoder(w io.Writer) io.WriteCloser {
+	switch s.Variant {
+	case Base64Url:
+		return base64.NewEncoder(base64.URLEncoding, w)
+	case Base64RawStd:
+		return base64.NewEncoder(base64.RawStdEncoding, w)
+	case Base64RawUrl:
+		return base64.NewEncoder(base64.RawURLEncoding, w)
+	case Base64Mime:
+		lw := &lineWriter{w: w, length: mimeLineLength, separator: []byte("\r\n")}
+		return base64.NewEncoder(base64.StdEncoding, lw)
+	}
+
+	return base64.NewEncoder(base64.StdEncoding, w)
+}
+
+// 创建流式解码器，可解码任意变体，并忽略空白字符
+func (s *Base64) NewDecoder(r io.Reader) io.Reader {
+	return NewBase64LenientDecoder(r)
+}
+
 func ToBase64String(val []byte) string {
 	return base64.StdEncoding.EncodeToString(val)
 }
@@ -20,3 +82,138 @@ func ToBase64String(val []byte) string {
 func FromBase64String(val string) ([]byte, error) {
 	return base64.StdEncoding.DecodeString(val)
 }
+
+func ToBase64UrlString(val []byte) string {
+	return base64.URLEncoding.EncodeToString(val)
+}
+
+func FromBase64UrlString(val string) ([]byte, error) {
+	return base64.URLEncoding.DecodeString(val)
+}
+
+func ToBase64RawUrlString(val []byte) string {
+	return base64.RawURLEncoding.EncodeToString(val)
+}
+
+func FromBase64RawUrlString(val string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(val)
+}
+
+func ToBase64MimeString(val []byte) string {
+	encoded := base64.StdEncoding.EncodeToString(val)
+	sb := &strings.Builder{}
+	for len(encoded) > mimeLineLength {
+		sb.WriteString(encoded[:mimeLineLength])
+		sb.WriteString("\r\n")
+		encoded = encoded[mimeLineLength:]
+	}
+	sb.WriteString(encoded)
+
+	return sb.String()
+}
+
+// 宽松解码：接受标准、URL安全、带或不带补齐及MIME换行的编码，并忽略空白字符
+func FromBase64LenientString(val string) ([]byte, error) {
+	val = strings.TrimRight(stripWhitespace(val), "=")
+	if strings.ContainsAny(val, "-_") {
+		if strings.ContainsAny(val, "+/") {
+			return nil, fmt.Errorf("invalid base64: mixed standard and url-safe alphabet")
+		}
+		return base64.RawURLEncoding.DecodeString(val)
+	}
+
+	return base64.RawStdEncoding.DecodeString(val)
+}
+
+// 创建宽松流式解码器，规则同FromBase64LenientString
+func NewBase64LenientDecoder(r io.Reader) io.Reader {
+	return base64.NewDecoder(base64.RawStdEncoding, &lenientReader{r: r})
+}
+
+func stripWhitespace(val string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\t' || r == '\r' || r == '\n' {
+			return -1
+		}
+		return r
+	}, val)
+}
+
+// 过滤空白字符及末尾的补齐，并将URL安全字符转换为标准字符
+// 补齐之后仅允许空白字符及补齐，不允许混用标准及URL安全字符，规则同FromBase64LenientString
+type lenientReader struct {
+	r       io.Reader
+	padding bool // 已读取到补齐
+	std     bool // 已读取到标准编码字符(+/)
+	url     bool // 已读取到URL安全字符(-_)
+}
+
+func (s *lenientReader) Read(p []byte) (int, error) {
+	for {
+		n, err := s.r.Read(p)
+		count := 0
+		for i := 0; i < n; i++ {
+			c := p[i]
+			switch c {
+			case ' ', '\t', '\r', '\n':
+				continue
+			case '=':
+				s.padding = true
+				continue
+			case '+', '/':
+				s.std = true
+			case '-':
+				s.url = true
+				c = '+'
+			case '_':
+				s.url = true
+				c = '/'
+			}
+			if s.padding {
+				return count, fmt.Errorf("invalid base64: data after padding")
+			}
+			if s.std && s.url {
+				return count, fmt.Errorf("invalid base64: mixed standard and url-safe alphabet")
+			}
+			p[count] = c
+			count++
+		}
+		if count > 0 || err != nil {
+			return count, err
+		}
+	}
+}
+
+// 每length个字符插入换行
+type lineWriter struct {
+	w         io.Writer
+	length    int
+	separator []byte
+	column    int
+}
+
+func (s *lineWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		if s.column == s.length {
+			_, err := s.w.Write(s.separator)
+			if err != nil {
+				return written, err
+			}
+			s.column = 0
+		}
+		n := s.length - s.column
+		if n > len(p) {
+			n = len(p)
+		}
+		n, err := s.w.Write(p[:n])
+		written += n
+		s.column += n
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+
+	return written, nil
+}
@@ -0,0 +1,101 @@
+package encoding
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestBase64_Variants(t *testing.T) {
+	data := []byte{0xfb, 0xff, 0xbf, 0x00, 0x10}
+	expected := map[int]string{
+		Base64Std:    "+/+/ABA=",
+		Base64Url:    "-_-_ABA=",
+		Base64RawStd: "+/+/ABA",
+		Base64RawUrl: "-_-_ABA",
+		Base64Mime:   "+/+/ABA=",
+	}
+	for variant, val := range expected {
+		b := &Base64{Variant: variant}
+		r := b.EncodeToString(data)
+		if r != val {
+			t.Errorf("variant %d: expected %s, got %s", variant, val, r)
+		}
+		d, err := b.DecodeFromString(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(d, data) {
+			t.Errorf("variant %d: decoded data mismatch", variant)
+		}
+		d, err = FromBase64LenientString(" " + r + "\r\n")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(d, data) {
+			t.Errorf("variant %d: lenient decoded data mismatch", variant)
+		}
+	}
+}
+
+func TestBase64_Stream(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 100)
+	b := &Base64{Variant: Base64Mime}
+
+	buf := &bytes.Buffer{}
+	w := b.NewEncoder(buf)
+	_, err := w.Write(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded := buf.String()
+	if encoded != ToBase64MimeString(data) {
+		t.Fatalf("stream encoded data mismatch")
+	}
+	for _, line := range strings.Split(encoded, "\r\n") {
+		if len(line) > 76 {
+			t.Fatalf("line too long: %d", len(line))
+		}
+	}
+
+	decoded, err := ioutil.ReadAll(b.NewDecoder(strings.NewReader(encoded)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded, data) {
+		t.Error("stream decoded data mismatch")
+	}
+}
+
+func TestBase64_LenientDecoder(t *testing.T) {
+	cases := []struct {
+		val   string
+		valid bool
+	}{
+		{"+/+/ABA=", true},
+		{"-_-_ABA", true},
+		{"+/+/\r\nABA= =\n", true},
+		{"+/+/AB=A", false},
+		{"QQ==QQ==", false},
+		{"+/-_ABA=", false},
+		{"-_+/ABA", false},
+	}
+	for _, c := range cases {
+		expected, err := FromBase64LenientString(c.val)
+		if (err == nil) != c.valid {
+			t.Errorf("%q: string decode valid=%v, expected %v", c.val, err == nil, c.valid)
+		}
+		decoded, err := ioutil.ReadAll(NewBase64LenientDecoder(strings.NewReader(c.val)))
+		if (err == nil) != c.valid {
+			t.Errorf("%q: stream decode valid=%v, expected %v", c.val, err == nil, c.valid)
+		}
+		if c.valid && !bytes.Equal(decoded, expected) {
+			t.Errorf("%q: stream and string decode mismatch", c.val)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package encoding
+
+import "strings"
+
+const (
+	NameHex             = "hex"
+	NameHexUpper        = "HEX"
+	NameBase32          = "base32"
+	NameBase32Crockford = "base32-crockford"
+	NameBase58          = "base58"
+	NameBase58Check     = "base58check"
+	NameBase64          = "base64"
+	NameBase64Url       = "base64url"
+	NameBase64RawStd    = "base64raw"
+	NameBase64RawUrl    = "base64rawurl"
+	NameBase64Mime      = "base64mime"
+)
+
+type Encoder interface {
+	EncodeToString(val []byte) string
+	DecodeFromString(val string) ([]byte, error)
+}
+
+// 根据名称获取编码实现，名称不区分大小写(hex/HEX除外)
+func NewEncoder(name string) Encoder {
+	if name == NameHexUpper {
+		return &Hex{Upper: true}
+	}
+
+	switch strings.ToLower(name) {
+	case NameHex:
+		return &Hex{}
+	case NameBase32:
+		return &Base32{}
+	case NameBase32Crockford:
+		return &Base32{Crockford: true}
+	case NameBase58:
+		return &Base58{}
+	case NameBase58Check:
+		return &Base58{Check: true}
+	case NameBase64:
+		return &Base64{Variant: Base64Std}
+	case NameBase64Url:
+		return &Base64{Variant: Base64Url}
+	case NameBase64RawStd:
+		return &Base64{Variant: Base64RawStd}
+	case NameBase64RawUrl:
+		return &Base64{Variant: Base64RawUrl}
+	case NameBase64Mime:
+		return &Base64{Variant: Base64Mime}
+	}
+
+	return nil
+}
@@ -0,0 +1,209 @@
+package encoding
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewEncoder(t *testing.T) {
+	data := []byte("Hello World!")
+	expected := map[string]string{
+		NameHex:             "48656c6c6f20576f726c6421",
+		NameHexUpper:        "48656C6C6F20576F726C6421",
+		NameBase32:          "JBSWY3DPEBLW64TMMQQQ====",
+		NameBase32Crockford: "91JPRV3F41BPYWKCCGGG",
+		NameBase58:          "2NEpo7TZRRrLZSi2U",
+		NameBase64:          "SGVsbG8gV29ybGQh",
+	}
+	for name, val := range expected {
+		encoder := NewEncoder(name)
+		if encoder == nil {
+			t.Fatalf("encoder '%s' not found", name)
+		}
+		r := encoder.EncodeToString(data)
+		if r != val {
+			t.Errorf("%s: expected %s, got %s", name, val, r)
+		}
+		d, err := encoder.DecodeFromString(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(d, data) {
+			t.Errorf("%s: decoded data mismatch", name)
+		}
+	}
+}
+
+func TestBase58_Check(t *testing.T) {
+	// 比特币地址：version(0x00) + hash160
+	payload, _ := hex.DecodeString("00f54a5851e9372b87810a8e60cdd2e7cfd80b6e31")
+	expected := "1PMycacnJaSqwwJqjawXBErnLsZ7RkXUAs"
+
+	encoder := &Base58{Check: true}
+	r := encoder.EncodeToString(payload)
+	if r != expected {
+		t.Fatalf("expected %s, got %s", expected, r)
+	}
+	d, err := encoder.DecodeFromString(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(d, payload) {
+		t.Error("decoded data mismatch")
+	}
+
+	_, err = encoder.DecodeFromString("1PMycacnJaSqwwJqjawXBErnLsZ7RkXUAt")
+	if err == nil {
+		t.Error("decode should fail with invalid checksum")
+	}
+}
+
+func TestAsn1Parser_Dump(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "asn1"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parser := &Asn1Parser{Encapsulated: true}
+	r, err := parser.DumpToString(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("\n" + r)
+
+	for _, val := range []string{"ecdsa-with-SHA256", "commonName", ":asn1", "UTCTIME"} {
+		if !strings.Contains(r, val) {
+			t.Errorf("dump should contain '%s'", val)
+		}
+	}
+
+	// 签名值(BIT STRING)中封装的ECDSA签名结构
+	nodes, err := parser.Parse(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := nodes[0].Children[2]
+	if len(signature.Children) != 1 || !signature.Children[0].Encapsulated {
+		t.Error("signature should be parsed as encapsulated structure")
+	}
+}
+
+func TestAsn1Parser_Indefinite(t *testing.T) {
+	// SEQUENCE(indefinite) { INTEGER 1, OCTET STRING "ab" } , NULL
+	data, _ := hex.DecodeString("30800201010402616200000500")
+	nodes, err := (&Asn1Parser{}).Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 2 || len(nodes[0].Children) != 2 || nodes[0].Length != -1 {
+		t.Fatalf("unexpected structure: %d nodes", len(nodes))
+	}
+	if nodes[1].Offset != 11 || nodes[1].Tag != 5 {
+		t.Errorf("unexpected sibling after indefinite length: offset=%d, tag=%d", nodes[1].Offset, nodes[1].Tag)
+	}
+}
+
+func TestAsn1Parser_IndefiniteNested(t *testing.T) {
+	// SEQUENCE(indefinite) { SEQUENCE(indefinite) { INTEGER 1 }, INTEGER 2 } , NULL
+	data, _ := hex.DecodeString("30803080020101000002010200000500")
+	nodes, err := (&Asn1Parser{}).Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 2 {
+		t.Fatalf("unexpected node count: %d", len(nodes))
+	}
+	outer := nodes[0]
+	if len(outer.Children) != 2 || len(outer.Children[0].Children) != 1 {
+		t.Fatalf("unexpected structure: %d children", len(outer.Children))
+	}
+	if len(outer.Bytes) != 10 {
+		t.Errorf("unexpected outer content length: %d", len(outer.Bytes))
+	}
+	if v := outer.Children[1]; v.Offset != 9 || v.Tag != 2 || v.Value != "2" {
+		t.Errorf("unexpected sibling after nested indefinite length: offset=%d, tag=%d, value=%s", v.Offset, v.Tag, v.Value)
+	}
+	if nodes[1].Offset != 14 || nodes[1].Tag != 5 {
+		t.Errorf("unexpected node after outer indefinite length: offset=%d, tag=%d", nodes[1].Offset, nodes[1].Tag)
+	}
+
+	buf := &bytes.Buffer{}
+	err = (&Asn1Parser{}).Dump(buf, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "univ [ 0 ]") || !strings.Contains(buf.String(), "NULL") {
+		t.Errorf("unexpected dump:\n%s", buf.String())
+	}
+}
+
+func TestAsn1Parser_EndOfContents(t *testing.T) {
+	cases := []string{
+		// 定长编码内容中的结束标记
+		"3005000002010105000500",
+		"3007000002010104ff",
+		// 顶层的结束标记
+		"000002010105000500",
+		"0500000002010101",
+	}
+	for _, v := range cases {
+		data, _ := hex.DecodeString(v)
+		_, err := (&Asn1Parser{}).Parse(data)
+		if err == nil || !strings.Contains(err.Error(), "end-of-contents") {
+			t.Errorf("%s: unexpected end-of-contents error expected, got %v", v, err)
+		}
+		_, err = (&Asn1Parser{}).ToDer(data)
+		if err == nil {
+			t.Errorf("%s: to der should fail", v)
+		}
+	}
+
+	// 长度字节数超过3
+	data, _ := hex.DecodeString("048400000001ff")
+	_, err := (&Asn1Parser{}).Parse(data)
+	if err == nil {
+		t.Error("length with 4 octets should fail")
+	}
+}
+
+func TestAsn1Parser_ToDer(t *testing.T) {
+	cases := map[string]string{
+		// 嵌套不定长编码
+		"30803080020101000002010200000500": "300830030201010201020500",
+		// 分段OCTET STRING
+		"308024800402616204016300000000": "30050403616263",
+		// 分段BIT STRING
+		"30802380030200610302066200000000": "30050303066162",
+		// DER保持不变
+		"300830030201010201020500": "300830030201010201020500",
+	}
+	for ber, der := range cases {
+		data, _ := hex.DecodeString(ber)
+		result, err := (&Asn1Parser{}).ToDer(data)
+		if err != nil {
+			t.Fatal(ber, err)
+		}
+		if hex.EncodeToString(result) != der {
+			t.Errorf("%s: expected %s, got %x", ber, der, result)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package encoding
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+type Hex struct {
+	Upper bool // 是否使用大写字母
+}
+
+func (s *Hex) EncodeToString(val []byte) string {
+	if s.Upper {
+		return strings.ToUpper(hex.EncodeToString(val))
+	}
+
+	return hex.EncodeToString(val)
+}
+
+// 解码时不区分大小写
+func (s *Hex) DecodeFromString(val string) ([]byte, error) {
+	return hex.DecodeString(val)
+}
@@ -0,0 +1,160 @@
+package hash
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+// 基于buzhash滚动哈希的内容定义分块(content-defined chunking)，用于备份数据去重
+// 滚动窗口、哈希表及分块条件均为固定值，相同的数据及参数在任意运行中得到相同的分块结果，
+// 因此不同运行生成的分块库可以共享；修改以下常量或哈希表生成方式将导致分块结果不兼容
+const (
+	ChunkerWindowSize = 64
+	ChunkerSeed       = 0x6373627963646331
+
+	DefaultChunkerAvgSize = 1024 * 1024
+)
+
+var chunkerTable = newChunkerTable(ChunkerSeed)
+
+type Chunk struct {
+	Offset int64  `json:"offset" note:"起始位置"`
+	Length int    `json:"length" note:"长度"`
+	Digest string `json:"digest" note:"摘要(multihash格式的16进制字符串，包含摘要算法)"`
+}
+
+type Chunker struct {
+	MinSize int  // 最小分块长度，默认AvgSize/4
+	AvgSize int  // 平均分块长度，须为2的幂，默认1MB
+	MaxSize int  // 最大分块长度，默认AvgSize*4
+	Hash    Hash // 分块摘要算法，须支持multihash编码，默认SHA256
+}
+
+// 分割数据并返回所有分块信息
+func (s *Chunker) Chunks(r io.Reader) ([]Chunk, error) {
+	chunks := make([]Chunk, 0)
+	err := s.Split(r, func(chunk *Chunk, data []byte) error {
+		chunks = append(chunks, *chunk)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return chunks, nil
+}
+
+// 分割数据，每得到一个分块调用一次handle，data仅在调用期间有效
+func (s *Chunker) Split(r io.Reader, handle func(chunk *Chunk, data []byte) error) error {
+	minSize, avgSize, maxSize, err := s.sizes()
+	if err != nil {
+		return err
+	}
+	h := s.Hash
+	if h == nil {
+		h = &Sha256{}
+	}
+	// 摘要以multihash格式保存，不同算法生成的分块摘要不会相同
+	_, err = MultihashCode(h)
+	if err != nil {
+		return err
+	}
+
+	mask := uint32(avgSize - 1)
+	reader := bufio.NewReaderSize(r, 64*1024)
+	buf := make([]byte, 0, maxSize)
+	offset := int64(0)
+	emit := func() error {
+		digest, err := MultihashEncodeToString(h, buf)
+		if err != nil {
+			return err
+		}
+		chunk := &Chunk{
+			Offset: offset,
+			Length: len(buf),
+			Digest: digest,
+		}
+		if handle != nil {
+			err = handle(chunk, buf)
+			if err != nil {
+				return err
+			}
+		}
+		offset += int64(len(buf))
+		buf = buf[:0]
+		return nil
+	}
+
+	roll := uint32(0)
+	for {
+		b, err := reader.ReadByte()
+		if err == io.EOF {
+			if len(buf) > 0 {
+				return emit()
+			}
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		buf = append(buf, b)
+		n := len(buf)
+		roll = bits.RotateLeft32(roll, 1) ^ chunkerTable[b]
+		if n > ChunkerWindowSize {
+			out := buf[n-1-ChunkerWindowSize]
+			roll ^= bits.RotateLeft32(chunkerTable[out], ChunkerWindowSize%32)
+		}
+
+		if n >= maxSize || (n >= minSize && roll&mask == 0) {
+			err = emit()
+			if err != nil {
+				return err
+			}
+			roll = 0
+		}
+	}
+}
+
+func (s *Chunker) sizes() (minSize, avgSize, maxSize int, err error) {
+	avgSize = s.AvgSize
+	if avgSize == 0 {
+		avgSize = DefaultChunkerAvgSize
+	}
+	if avgSize < ChunkerWindowSize || avgSize&(avgSize-1) != 0 {
+		err = fmt.Errorf("invalid average size: %d, must be a power of 2 and not less than %d", avgSize, ChunkerWindowSize)
+		return
+	}
+
+	minSize = s.MinSize
+	if minSize == 0 {
+		minSize = avgSize / 4
+	}
+	maxSize = s.MaxSize
+	if maxSize == 0 {
+		maxSize = avgSize * 4
+	}
+	if minSize < ChunkerWindowSize || minSize > avgSize || maxSize < avgSize {
+		err = fmt.Errorf("invalid chunk size: min=%d, avg=%d, max=%d", minSize, avgSize, maxSize)
+		return
+	}
+
+	return
+}
+
+// 使用splitmix64生成固定的哈希表，保证不同平台及版本下结果一致
+func newChunkerTable(seed uint64) [256]uint32 {
+	table := [256]uint32{}
+	x := seed
+	for i := range table {
+		x += 0x9e3779b97f4a7c15
+		z := x
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		z = z ^ (z >> 31)
+		table[i] = uint32(z >> 32)
+	}
+
+	return table
+}
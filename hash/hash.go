@@ -3,6 +3,8 @@ package hash
 import (
 	"crypto"
 	"encoding/hex"
+	"fmt"
+	"github.com/csby/security/encoding"
 )
 
 const (
@@ -41,6 +43,22 @@ func NewHash(format uint64) Hash {
 	return nil
 }
 
+// 计算数据摘要，并使用指定的编码(如：encoding.NewEncoder("base58"))转换为字符串
+func HashToEncodedString(h Hash, data []byte, encoder encoding.Encoder) (string, error) {
+	if h == nil {
+		return "", fmt.Errorf("invalid hash: nil")
+	}
+	if encoder == nil {
+		return "", fmt.Errorf("invalid encoder: nil")
+	}
+	hashed, err := h.Hash(data)
+	if err != nil {
+		return "", err
+	}
+
+	return encoder.EncodeToString(hashed), nil
+}
+
 type hash struct {
 }
 
@@ -0,0 +1,24 @@
+package hash
+
+import (
+	"crypto/hmac"
+	"fmt"
+)
+
+// 计算数据的HMAC值，摘要算法由h指定
+func Hmac(h Hash, key, data []byte) ([]byte, error) {
+	if h == nil {
+		return nil, fmt.Errorf("invalid hash: nil")
+	}
+	if !h.Type().Available() {
+		return nil, fmt.Errorf("not support hash: %T", h)
+	}
+
+	mac := hmac.New(h.Type().New, key)
+	_, err := mac.Write(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return mac.Sum(nil), nil
+}
@@ -0,0 +1,205 @@
+package hash
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	gohash "hash"
+	"hash/adler32"
+	"hash/crc32"
+	"io"
+	"os"
+	"sync"
+)
+
+const (
+	multiHashChunkSize         = 256 * 1024
+	multiHashParallelThreshold = 4 * 1024 * 1024
+)
+
+// 单次读取数据同时计算多种摘要
+// 数据量超过阈值时，每种算法使用独立的协程并行计算
+type MultiHash struct {
+	Formats   []uint64 // MD5, SHA1, SHA256, SHA384, SHA512, CRC32, ADLER32
+	Threshold int64    // 启用并行计算的数据量阈值(字节)，默认4MB，小于0时始终顺序计算
+}
+
+func (s *MultiHash) Hash(data []byte) (map[uint64][]byte, error) {
+	hashers, err := s.hashers()
+	if err != nil {
+		return nil, err
+	}
+
+	if s.threshold() < 0 || int64(len(data)) < s.threshold() {
+		for _, h := range hashers {
+			h.Write(data)
+		}
+	} else {
+		wg := &sync.WaitGroup{}
+		for _, h := range hashers {
+			wg.Add(1)
+			go func(h gohash.Hash) {
+				defer wg.Done()
+				h.Write(data)
+			}(h)
+		}
+		wg.Wait()
+	}
+
+	return s.sum(hashers), nil
+}
+
+func (s *MultiHash) HashToString(data []byte) (map[uint64]string, error) {
+	hashed, err := s.Hash(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.ToString(hashed), nil
+}
+
+func (s *MultiHash) HashFile(path string) (map[uint64][]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return s.HashReader(file)
+}
+
+func (s *MultiHash) HashReader(r io.Reader) (map[uint64][]byte, error) {
+	hashers, err := s.hashers()
+	if err != nil {
+		return nil, err
+	}
+
+	// 数据量未超过阈值前顺序计算
+	threshold := s.threshold()
+	total := int64(0)
+	buf := make([]byte, multiHashChunkSize)
+	for threshold < 0 || total < threshold {
+		n, err := r.Read(buf)
+		if n > 0 {
+			total += int64(n)
+			for _, h := range hashers {
+				h.Write(buf[:n])
+			}
+		}
+		if err == io.EOF {
+			return s.sum(hashers), nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	// 超过阈值后分发到各算法的协程
+	wg := &sync.WaitGroup{}
+	channels := make([]chan []byte, 0, len(hashers))
+	for _, h := range hashers {
+		ch := make(chan []byte, 4)
+		channels = append(channels, ch)
+		wg.Add(1)
+		go func(h gohash.Hash, ch chan []byte) {
+			defer wg.Done()
+			for chunk := range ch {
+				h.Write(chunk)
+			}
+		}(h, ch)
+	}
+	dispatch := func(chunk []byte) {
+		for _, ch := range channels {
+			ch <- chunk
+		}
+	}
+	finish := func() {
+		for _, ch := range channels {
+			close(ch)
+		}
+		wg.Wait()
+	}
+
+	for {
+		// 各协程共享同一数据块，因此每次读取使用新的缓冲区
+		chunk := make([]byte, multiHashChunkSize)
+		n, err := r.Read(chunk)
+		if n > 0 {
+			dispatch(chunk[:n])
+		}
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			finish()
+			return nil, err
+		}
+	}
+	finish()
+
+	return s.sum(hashers), nil
+}
+
+func (s *MultiHash) ToString(hashed map[uint64][]byte) map[uint64]string {
+	h := &hash{}
+	results := make(map[uint64]string, len(hashed))
+	for format, val := range hashed {
+		results[format] = h.ToString(val)
+	}
+
+	return results
+}
+
+func (s *MultiHash) hashers() (map[uint64]gohash.Hash, error) {
+	if len(s.Formats) < 1 {
+		return nil, fmt.Errorf("invalid formats: empty")
+	}
+
+	hashers := make(map[uint64]gohash.Hash, len(s.Formats))
+	for _, format := range s.Formats {
+		h := newHasher(format)
+		if h == nil {
+			return nil, fmt.Errorf("not support format: %d", format)
+		}
+		hashers[format] = h
+	}
+
+	return hashers, nil
+}
+
+func (s *MultiHash) sum(hashers map[uint64]gohash.Hash) map[uint64][]byte {
+	results := make(map[uint64][]byte, len(hashers))
+	for format, h := range hashers {
+		results[format] = h.Sum(nil)
+	}
+
+	return results
+}
+
+func (s *MultiHash) threshold() int64 {
+	if s.Threshold == 0 {
+		return multiHashParallelThreshold
+	}
+
+	return s.Threshold
+}
+
+func newHasher(format uint64) gohash.Hash {
+	if format == MD5 {
+		return md5.New()
+	} else if format == SHA1 {
+		return sha1.New()
+	} else if format == SHA256 {
+		return sha256.New()
+	} else if format == SHA384 {
+		return sha512.New384()
+	} else if format == SHA512 {
+		return sha512.New()
+	} else if format == CRC32 {
+		return crc32.NewIEEE()
+	} else if format == ADLER32 {
+		return adler32.New()
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package hash
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+)
+
+// multihash编码，参考：https://multiformats.io/multihash/
+// 格式：|varint(code)|varint(length)|digest|
+const (
+	MultihashSha1   = 0x11
+	MultihashSha256 = 0x12
+	MultihashSha512 = 0x13
+	MultihashSha384 = 0x20
+	MultihashMd5    = 0xd5
+	MultihashCrc32  = 0x0132
+)
+
+// 根据哈希实现获取multihash编码
+func MultihashCode(h Hash) (uint64, error) {
+	switch h.(type) {
+	case *Md5:
+		return MultihashMd5, nil
+	case *Sha1:
+		return MultihashSha1, nil
+	case *Sha256:
+		return MultihashSha256, nil
+	case *Sha384:
+		return MultihashSha384, nil
+	case *Sha512:
+		return MultihashSha512, nil
+	case *Crc:
+		return MultihashCrc32, nil
+	}
+
+	return 0, fmt.Errorf("not support hash: %T", h)
+}
+
+// 根据multihash编码获取哈希实现
+func NewMultihash(code uint64) Hash {
+	switch code {
+	case MultihashMd5:
+		return &Md5{}
+	case MultihashSha1:
+		return &Sha1{}
+	case MultihashSha256:
+		return &Sha256{}
+	case MultihashSha384:
+		return &Sha384{}
+	case MultihashSha512:
+		return &Sha512{}
+	case MultihashCrc32:
+		return &Crc{}
+	}
+
+	return nil
+}
+
+// 计算数据摘要并以multihash格式返回
+func MultihashEncode(h Hash, data []byte) ([]byte, error) {
+	if h == nil {
+		return nil, fmt.Errorf("invalid hash: nil")
+	}
+	hashed, err := h.Hash(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return MultihashFromDigest(h, hashed)
+}
+
+// 计算数据摘要并以multihash格式的16进制字符串返回
+func MultihashEncodeToString(h Hash, data []byte) (string, error) {
+	mh, err := MultihashEncode(h, data)
+	if err != nil {
+		return "", err
+	}
+
+	return (&hash{}).ToString(mh), nil
+}
+
+// 将已有摘要封装为multihash格式
+func MultihashFromDigest(h Hash, digest []byte) ([]byte, error) {
+	code, err := MultihashCode(h)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 0, 2*binary.MaxVarintLen64+len(digest))
+	buf = appendUvarint(buf, code)
+	buf = appendUvarint(buf, uint64(len(digest)))
+	buf = append(buf, digest...)
+
+	return buf, nil
+}
+
+// 解析multihash格式，返回对应的哈希实现及摘要
+func MultihashDecode(mh []byte) (Hash, []byte, error) {
+	code, n := binary.Uvarint(mh)
+	if n <= 0 {
+		return nil, nil, fmt.Errorf("invalid multihash: bad code")
+	}
+	mh = mh[n:]
+	length, n := binary.Uvarint(mh)
+	if n <= 0 {
+		return nil, nil, fmt.Errorf("invalid multihash: bad length")
+	}
+	mh = mh[n:]
+	if uint64(len(mh)) != length {
+		return nil, nil, fmt.Errorf("invalid multihash: length %d, actual %d", length, len(mh))
+	}
+
+	if length == 0 {
+		return nil, nil, fmt.Errorf("invalid multihash: empty digest")
+	}
+
+	h := NewMultihash(code)
+	if h == nil {
+		return nil, nil, fmt.Errorf("not support multihash code: 0x%x", code)
+	}
+
+	return h, mh, nil
+}
+
+// 解析16进制字符串形式的multihash
+func MultihashDecodeString(val string) (Hash, []byte, error) {
+	mh, err := (&hash{}).ToData(val)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return MultihashDecode(mh)
+}
+
+// 使用multihash中指定的算法校验数据，摘要须为完整长度，不接受截断的摘要
+func MultihashVerify(mh []byte, data []byte) error {
+	h, digest, err := MultihashDecode(mh)
+	if err != nil {
+		return err
+	}
+
+	hashed, err := h.Hash(data)
+	if err != nil {
+		return err
+	}
+	if len(digest) != len(hashed) {
+		return fmt.Errorf("invalid multihash: digest length %d, expected %d", len(digest), len(hashed))
+	}
+	if subtle.ConstantTimeCompare(hashed, digest) != 1 {
+		return fmt.Errorf("multihash mismatch")
+	}
+
+	return nil
+}
+
+// 使用16进制字符串形式multihash中指定的算法校验数据
+func MultihashVerifyString(val string, data []byte) error {
+	mh, err := (&hash{}).ToData(val)
+	if err != nil {
+		return err
+	}
+
+	return MultihashVerify(mh, data)
+}
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	tmp := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(tmp, v)
+
+	return append(buf, tmp[:n]...)
+}
@@ -0,0 +1,24 @@
+package hash
+
+import (
+	"crypto/pbkdf2"
+	"fmt"
+)
+
+// PBKDF2密钥派生(HMAC)，参考：RFC 8018 5.2，使用标准库crypto/pbkdf2实现
+func Pbkdf2(h Hash, password, salt []byte, iterations, keyLength int) ([]byte, error) {
+	if h == nil {
+		return nil, fmt.Errorf("invalid hash: nil")
+	}
+	if !h.Type().Available() {
+		return nil, fmt.Errorf("not support hash: %T", h)
+	}
+	if iterations < 1 {
+		return nil, fmt.Errorf("invalid iterations: %d", iterations)
+	}
+	if keyLength < 1 {
+		return nil, fmt.Errorf("invalid key length: %d", keyLength)
+	}
+
+	return pbkdf2.Key(h.Type().New, string(password), salt, iterations, keyLength)
+}
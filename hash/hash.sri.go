@@ -0,0 +1,141 @@
+package hash
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"github.com/csby/security/encoding"
+	"strings"
+)
+
+// W3C子资源完整性(Subresource Integrity)，参考：https://www.w3.org/TR/SRI/
+// 格式：<algorithm>-<base64(digest)>，如：sha384-oqVuAfXRKap7fdgcCY5uykM6+R9GqQ8K/uxy9rx7HNQlGYl1kPzQho1wx4JwY8wC
+// 多个值以空格分隔，校验时仅使用其中强度最高的算法
+
+// 根据哈希实现获取SRI算法名称
+func SriAlgorithm(h Hash) (string, error) {
+	switch h.(type) {
+	case *Sha256:
+		return "sha256", nil
+	case *Sha384:
+		return "sha384", nil
+	case *Sha512:
+		return "sha512", nil
+	}
+
+	return "", fmt.Errorf("not support hash: %T", h)
+}
+
+// 根据SRI算法名称获取哈希实现
+func NewSri(algorithm string) Hash {
+	switch strings.ToLower(algorithm) {
+	case "sha256":
+		return &Sha256{}
+	case "sha384":
+		return &Sha384{}
+	case "sha512":
+		return &Sha512{}
+	}
+
+	return nil
+}
+
+// 计算数据摘要并返回SRI字符串
+func SriEncode(h Hash, data []byte) (string, error) {
+	if h == nil {
+		h = &Sha384{}
+	}
+	algorithm, err := SriAlgorithm(h)
+	if err != nil {
+		return "", err
+	}
+	hashed, err := h.Hash(data)
+	if err != nil {
+		return "", err
+	}
+
+	return algorithm + "-" + encoding.ToBase64String(hashed), nil
+}
+
+// 解析单个SRI值，返回对应的哈希实现及摘要
+func SriDecode(val string) (Hash, []byte, error) {
+	val = strings.TrimSpace(val)
+	// 忽略可选参数，如：sha384-xxx?foo
+	if idx := strings.Index(val, "?"); idx >= 0 {
+		val = val[:idx]
+	}
+	idx := strings.Index(val, "-")
+	if idx <= 0 {
+		return nil, nil, fmt.Errorf("invalid integrity: %s", val)
+	}
+
+	h := NewSri(val[:idx])
+	if h == nil {
+		return nil, nil, fmt.Errorf("not support integrity algorithm: %s", val[:idx])
+	}
+	digest, err := encoding.FromBase64String(val[idx+1:])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid integrity digest: %v", err)
+	}
+
+	return h, digest, nil
+}
+
+// 使用SRI值(可包含多个以空格分隔的值)校验数据
+// 按规范仅使用其中强度最高的算法，只要有一个摘要匹配即校验通过
+func SriVerify(integrity string, data []byte) error {
+	type item struct {
+		h      Hash
+		digest []byte
+	}
+	items := make([]item, 0)
+	strongest := 0
+	for _, val := range strings.Fields(integrity) {
+		h, digest, err := SriDecode(val)
+		if err != nil {
+			// 按规范忽略无法识别的值
+			continue
+		}
+		if priority := sriPriority(h); priority > strongest {
+			strongest = priority
+		}
+		items = append(items, item{h: h, digest: digest})
+	}
+	if len(items) < 1 {
+		return fmt.Errorf("invalid integrity: no supported value")
+	}
+
+	hashes := make(map[int][]byte)
+	for _, v := range items {
+		priority := sriPriority(v.h)
+		if priority != strongest {
+			continue
+		}
+		hashed, ok := hashes[priority]
+		if !ok {
+			var err error
+			hashed, err = v.h.Hash(data)
+			if err != nil {
+				return err
+			}
+			hashes[priority] = hashed
+		}
+		if subtle.ConstantTimeCompare(hashed, v.digest) == 1 {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("integrity mismatch")
+}
+
+func sriPriority(h Hash) int {
+	switch h.(type) {
+	case *Sha256:
+		return 1
+	case *Sha384:
+		return 2
+	case *Sha512:
+		return 3
+	}
+
+	return 0
+}
@@ -1,6 +1,10 @@
 package hash
 
-import "testing"
+import (
+	"bytes"
+	"github.com/csby/security/encoding"
+	"testing"
+)
 
 const (
 	toHashData = "/api/login"
@@ -32,3 +36,186 @@ func TestAdler_HashToString(t *testing.T) {
 	}
 	t.Logf("%-8s %s", "Adler32", r)
 }
+
+func TestMultihash(t *testing.T) {
+	// sha2-256("foo")
+	mh, e := MultihashEncodeToString(&Sha256{}, []byte("foo"))
+	if e != nil {
+		t.Fatal(e)
+	}
+	expected := "12202c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+	if mh != expected {
+		t.Fatalf("expected %s, got %s", expected, mh)
+	}
+
+	h, digest, e := MultihashDecodeString(mh)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if _, ok := h.(*Sha256); !ok {
+		t.Errorf("expected sha256, got %T", h)
+	}
+	if len(digest) != 32 {
+		t.Errorf("expected digest length 32, got %d", len(digest))
+	}
+
+	if e = MultihashVerifyString(mh, []byte("foo")); e != nil {
+		t.Error(e)
+	}
+	if e = MultihashVerifyString(mh, []byte("bar")); e == nil {
+		t.Error("verify should fail for different content")
+	}
+
+	// 截断的摘要
+	truncated, e := MultihashFromDigest(&Sha256{}, digest[:1])
+	if e != nil {
+		t.Fatal(e)
+	}
+	if e = MultihashVerify(truncated, []byte("foo")); e == nil {
+		t.Error("verify should fail for truncated digest")
+	}
+}
+
+func TestSri(t *testing.T) {
+	// echo -n "alert('Hello, world.');" | openssl dgst -sha384 -binary | openssl base64 -A
+	data := []byte("alert('Hello, world.');")
+	expected := "sha384-H8BRh8j48O9oYatfu5AZzq6A9RINhZO5H16dQZngK7T62em8MUt1FLm52t+eX6xO"
+	sri, e := SriEncode(&Sha384{}, data)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if sri != expected {
+		t.Fatalf("expected %s, got %s", expected, sri)
+	}
+
+	if e = SriVerify(expected, data); e != nil {
+		t.Error(e)
+	}
+	if e = SriVerify("sha256-invalid "+expected, data); e != nil {
+		t.Error(e)
+	}
+	if e = SriVerify("sha512-AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA== "+expected, data); e == nil {
+		t.Error("verify should use the strongest algorithm only")
+	}
+}
+
+func TestMultiHash_HashReader(t *testing.T) {
+	formats := []uint64{MD5, SHA1, SHA256, CRC32}
+	data := bytes.Repeat([]byte(toHashData), 100000)
+
+	for _, threshold := range []int64{-1, 1024} {
+		mh := &MultiHash{Formats: formats, Threshold: threshold}
+		results, e := mh.HashReader(bytes.NewReader(data))
+		if e != nil {
+			t.Fatal(e)
+		}
+		if len(results) != len(formats) {
+			t.Fatalf("expected %d results, got %d", len(formats), len(results))
+		}
+		for _, format := range formats {
+			expected, e := NewHash(format).Hash(data)
+			if e != nil {
+				t.Fatal(e)
+			}
+			if !bytes.Equal(results[format], expected) {
+				t.Errorf("threshold %d, format %d: expected %x, got %x", threshold, format, expected, results[format])
+			}
+		}
+	}
+}
+
+func TestChunker_Chunks(t *testing.T) {
+	data := testChunkerData(1024 * 1024)
+	chunker := &Chunker{MinSize: 1024, AvgSize: 4096, MaxSize: 16384}
+
+	chunks, e := chunker.Chunks(bytes.NewReader(data))
+	if e != nil {
+		t.Fatal(e)
+	}
+	offset := int64(0)
+	for _, c := range chunks {
+		if c.Offset != offset {
+			t.Fatalf("expected offset %d, got %d", offset, c.Offset)
+		}
+		if c.Length > chunker.MaxSize {
+			t.Fatalf("chunk length %d exceeds max size", c.Length)
+		}
+		offset += int64(c.Length)
+	}
+	if offset != int64(len(data)) {
+		t.Fatalf("expected total length %d, got %d", len(data), offset)
+	}
+	t.Logf("%d chunks, average length %d", len(chunks), len(data)/len(chunks))
+
+	// 分块结果须在不同运行及版本间保持一致
+	expected := []Chunk{
+		{Offset: 0, Length: 1279, Digest: "1220c7f78da9807e3c1f512a4d2a3a9f573a48c4c52d278aeebad530883fa03665f0"},
+		{Offset: 1279, Length: 4890, Digest: "1220657bbc24a46a8b2184dcbc9048279b24afe945b108f267d3ee5bbecbd96e02ae"},
+		{Offset: 6169, Length: 2193, Digest: "1220e473b7c4e0d7b7676fca596aa52c45a5ce9fa11f417276a4664541c4d8a5f7ca"},
+	}
+	for i, c := range expected {
+		if chunks[i] != c {
+			t.Errorf("chunk %d: expected %+v, got %+v", i, c, chunks[i])
+		}
+	}
+
+	// 在数据头部插入内容后，后续分块应保持不变
+	shifted, e := chunker.Chunks(bytes.NewReader(append([]byte("inserted"), data...)))
+	if e != nil {
+		t.Fatal(e)
+	}
+	digests := make(map[string]bool)
+	for _, c := range chunks {
+		digests[c.Digest] = true
+	}
+	shared := 0
+	for _, c := range shifted {
+		if digests[c.Digest] {
+			shared++
+		}
+	}
+	if shared < len(chunks)-2 {
+		t.Errorf("expected at least %d shared chunks, got %d", len(chunks)-2, shared)
+	}
+
+	// 摘要包含算法，不同算法的分块摘要不相同
+	other := *chunker
+	other.Hash = &Sha512{}
+	otherChunks, e := other.Chunks(bytes.NewReader(data))
+	if e != nil {
+		t.Fatal(e)
+	}
+	for i, c := range otherChunks {
+		if c.Digest == chunks[i].Digest {
+			t.Errorf("chunk %d: digests of different hashes should differ", i)
+		}
+		h, _, e := MultihashDecodeString(c.Digest)
+		if e != nil {
+			t.Fatal(e)
+		}
+		if _, ok := h.(*Sha512); !ok {
+			t.Errorf("chunk %d: expected sha512 digest, got %T", i, h)
+		}
+	}
+}
+
+func testChunkerData(size int) []byte {
+	data := make([]byte, size)
+	x := uint32(1)
+	for i := range data {
+		x ^= x << 13
+		x ^= x >> 17
+		x ^= x << 5
+		data[i] = byte(x)
+	}
+
+	return data
+}
+
+func TestHashToEncodedString(t *testing.T) {
+	r, e := HashToEncodedString(&Sha256{}, []byte(toHashData), encoding.NewEncoder(encoding.NameBase58))
+	if e != nil {
+		t.Fatal(e)
+	}
+	t.Logf("%-8s %s", "SHA256", r)
+}
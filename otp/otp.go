@@ -0,0 +1,178 @@
+package otp
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"github.com/csby/security/hash"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultDigits       = 6
+	DefaultSecretLength = 20
+)
+
+// 基于HMAC的一次性密码(HOTP)，参考：RFC 4226
+type Hotp struct {
+	Secret    []byte
+	Algorithm uint64 // hash.SHA1(default), hash.SHA256 or hash.SHA512
+	Digits    int    // 密码位数(6~10)，默认6
+}
+
+// 根据计数器生成密码
+func (s *Hotp) Generate(counter uint64) (string, error) {
+	if len(s.Secret) < 1 {
+		return "", fmt.Errorf("invalid secret: empty")
+	}
+	h, _, err := s.hash()
+	if err != nil {
+		return "", err
+	}
+	digits, err := s.digits()
+	if err != nil {
+		return "", err
+	}
+
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, counter)
+	sum, err := hash.Hmac(h, s.Secret, msg)
+	if err != nil {
+		return "", err
+	}
+
+	// 动态截断
+	offset := sum[len(sum)-1] & 0x0f
+	code := uint64(binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff)
+	mod := uint64(1)
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", digits, code%mod), nil
+}
+
+// 校验密码，在[counter, counter+window]范围内查找匹配的计数器
+// 校验成功时返回匹配的计数器，调用者应将计数器更新为该值加1
+func (s *Hotp) Verify(code string, counter uint64, window int) (uint64, bool, error) {
+	if window < 0 {
+		window = 0
+	}
+	for i := 0; i <= window; i++ {
+		expected, err := s.Generate(counter + uint64(i))
+		if err != nil {
+			return 0, false, err
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return counter + uint64(i), true, nil
+		}
+	}
+
+	return 0, false, nil
+}
+
+// 生成身份验证器应用注册使用的otpauth://hotp/...地址
+func (s *Hotp) URI(issuer, account string, counter uint64) (string, error) {
+	params, err := s.params(issuer)
+	if err != nil {
+		return "", err
+	}
+	params.Set("counter", strconv.FormatUint(counter, 10))
+
+	return s.uri("hotp", issuer, account, params), nil
+}
+
+func (s *Hotp) params(issuer string) (url.Values, error) {
+	if len(s.Secret) < 1 {
+		return nil, fmt.Errorf("invalid secret: empty")
+	}
+	_, name, err := s.hash()
+	if err != nil {
+		return nil, err
+	}
+	digits, err := s.digits()
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("secret", SecretToBase32(s.Secret))
+	if len(issuer) > 0 {
+		params.Set("issuer", issuer)
+	}
+	params.Set("algorithm", name)
+	params.Set("digits", strconv.Itoa(digits))
+
+	return params, nil
+}
+
+func (s *Hotp) uri(kind, issuer, account string, params url.Values) string {
+	label := account
+	if len(issuer) > 0 {
+		label = issuer + ":" + account
+	}
+	u := &url.URL{
+		Scheme:   "otpauth",
+		Host:     kind,
+		Path:     "/" + label,
+		RawQuery: strings.Replace(params.Encode(), "+", "%20", -1),
+	}
+
+	return u.String()
+}
+
+func (s *Hotp) hash() (hash.Hash, string, error) {
+	if s.Algorithm == 0 || s.Algorithm == hash.SHA1 {
+		return &hash.Sha1{}, "SHA1", nil
+	} else if s.Algorithm == hash.SHA256 {
+		return &hash.Sha256{}, "SHA256", nil
+	} else if s.Algorithm == hash.SHA512 {
+		return &hash.Sha512{}, "SHA512", nil
+	}
+
+	return nil, "", fmt.Errorf("not support algorithm: %d", s.Algorithm)
+}
+
+// 密码位数，0时为默认值，有效范围为6~10
+func (s *Hotp) digits() (int, error) {
+	if s.Digits == 0 {
+		return DefaultDigits, nil
+	}
+	if s.Digits < 6 || s.Digits > 10 {
+		return 0, fmt.Errorf("invalid digits: %d", s.Digits)
+	}
+
+	return s.Digits, nil
+}
+
+// 生成随机密钥，length为密钥字节数，默认20
+func GenerateSecret(length int) ([]byte, error) {
+	if length < 1 {
+		length = DefaultSecretLength
+	}
+	secret := make([]byte, length)
+	_, err := io.ReadFull(rand.Reader, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// 密钥转换为base32字符串(无补齐)
+func SecretToBase32(secret []byte) string {
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret)
+}
+
+// 解析base32字符串密钥，忽略空格、大小写及补齐
+func SecretFromBase32(val string) ([]byte, error) {
+	val = strings.ToUpper(strings.Replace(val, " ", "", -1))
+	val = strings.TrimRight(val, "=")
+
+	return base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(val)
+}
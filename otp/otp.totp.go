@@ -0,0 +1,87 @@
+package otp
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultPeriod = 30
+)
+
+// 基于时间的一次性密码(TOTP)，参考：RFC 6238
+type Totp struct {
+	Hotp
+
+	Period int64 // 时间步长(秒)，默认30
+	Skew   int   // 允许前后偏差的时间步数，0表示仅校验当前时间步
+}
+
+// 生成指定时间的密码
+func (s *Totp) Generate(t time.Time) (string, error) {
+	counter, err := s.counter(t)
+	if err != nil {
+		return "", err
+	}
+
+	return s.Hotp.Generate(counter)
+}
+
+// 生成当前时间的密码
+func (s *Totp) Now() (string, error) {
+	return s.Generate(time.Now())
+}
+
+// 校验指定时间的密码，允许前后Skew个时间步的偏差，校验成功时返回匹配的时间步
+// 同一密码在偏差范围内可多次通过校验，调用者应保存最后一次成功的时间步，
+// 并拒绝不大于该值的时间步，防止重放(RFC 6238 5.2)
+func (s *Totp) Verify(code string, t time.Time) (uint64, bool, error) {
+	counter, err := s.counter(t)
+	if err != nil {
+		return 0, false, err
+	}
+
+	skew := uint64(0)
+	if s.Skew > 0 {
+		skew = uint64(s.Skew)
+	}
+	start := uint64(0)
+	if counter > skew {
+		start = counter - skew
+	}
+
+	return s.Hotp.Verify(code, start, int(counter+skew-start))
+}
+
+// 生成身份验证器应用注册使用的otpauth://totp/...地址
+func (s *Totp) URI(issuer, account string) (string, error) {
+	params, err := s.params(issuer)
+	if err != nil {
+		return "", err
+	}
+	params.Set("period", strconv.FormatInt(s.period(), 10))
+
+	return s.uri("totp", issuer, account, params), nil
+}
+
+func (s *Totp) counter(t time.Time) (uint64, error) {
+	period := s.period()
+	if period < 1 {
+		return 0, fmt.Errorf("invalid period: %d", period)
+	}
+	unix := t.Unix()
+	if unix < 0 {
+		return 0, fmt.Errorf("invalid time: %v", t)
+	}
+
+	return uint64(unix / period), nil
+}
+
+func (s *Totp) period() int64 {
+	if s.Period == 0 {
+		return DefaultPeriod
+	}
+
+	return s.Period
+}
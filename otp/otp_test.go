@@ -0,0 +1,130 @@
+package otp
+
+import (
+	"github.com/csby/security/hash"
+	"testing"
+	"time"
+)
+
+func TestHotp_Generate(t *testing.T) {
+	// RFC 4226 Appendix D
+	hotp := &Hotp{Secret: []byte("12345678901234567890")}
+	expected := []string{
+		"755224", "287082", "359152", "969429", "338314",
+		"254676", "287922", "162583", "399871", "520489",
+	}
+	for counter, code := range expected {
+		r, err := hotp.Generate(uint64(counter))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r != code {
+			t.Errorf("counter %d: expected %s, got %s", counter, code, r)
+		}
+	}
+
+	matched, ok, err := hotp.Verify("969429", 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || matched != 3 {
+		t.Errorf("expected matched counter 3, got %d (%v)", matched, ok)
+	}
+
+	for _, digits := range []int{-1, 5, 11} {
+		invalid := &Hotp{Secret: hotp.Secret, Digits: digits}
+		_, err = invalid.Generate(0)
+		if err == nil {
+			t.Errorf("digits %d: generate should fail", digits)
+		}
+		_, err = invalid.URI("ACME Co", "john@example.com", 0)
+		if err == nil {
+			t.Errorf("digits %d: uri should fail", digits)
+		}
+	}
+}
+
+func TestTotp_Generate(t *testing.T) {
+	// RFC 6238 Appendix B
+	secrets := map[uint64]string{
+		hash.SHA1:   "12345678901234567890",
+		hash.SHA256: "12345678901234567890123456789012",
+		hash.SHA512: "1234567890123456789012345678901234567890123456789012345678901234",
+	}
+	vectors := []struct {
+		unix      int64
+		algorithm uint64
+		code      string
+	}{
+		{59, hash.SHA1, "94287082"},
+		{59, hash.SHA256, "46119246"},
+		{59, hash.SHA512, "90693936"},
+		{1111111109, hash.SHA1, "07081804"},
+		{1111111109, hash.SHA256, "68084774"},
+		{1111111109, hash.SHA512, "25091201"},
+		{20000000000, hash.SHA1, "65353130"},
+		{20000000000, hash.SHA256, "77737706"},
+		{20000000000, hash.SHA512, "47863826"},
+	}
+	for _, v := range vectors {
+		totp := &Totp{
+			Hotp: Hotp{
+				Secret:    []byte(secrets[v.algorithm]),
+				Algorithm: v.algorithm,
+				Digits:    8,
+			},
+		}
+		r, err := totp.Generate(time.Unix(v.unix, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r != v.code {
+			t.Errorf("time %d, algorithm %d: expected %s, got %s", v.unix, v.algorithm, v.code, r)
+		}
+	}
+}
+
+func TestTotp_Verify(t *testing.T) {
+	secret, err := GenerateSecret(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	totp := &Totp{Hotp: Hotp{Secret: secret}, Skew: 1}
+	now := time.Now()
+	code, err := totp.Generate(now.Add(-30 * time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	step, ok, err := totp.Verify(code, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Error("verify should succeed within skew window")
+	}
+	if expected := uint64(now.Add(-30*time.Second).Unix() / DefaultPeriod); step != expected {
+		t.Errorf("expected step %d, got %d", expected, step)
+	}
+	_, ok, err = totp.Verify(code, now.Add(90*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Error("verify should fail outside skew window")
+	}
+
+	uri, err := totp.URI("ACME Co", "john@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(uri)
+
+	decoded, err := SecretFromBase32(SecretToBase32(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(decoded) != string(secret) {
+		t.Error("base32 secret mismatch")
+	}
+}
@@ -0,0 +1,185 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"github.com/csby/security/hash"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultSaltLength = 16
+
+	// OWASP Password Storage Cheat Sheet 推荐值
+	DefaultIterationsSha256 = 600000
+	DefaultIterationsSha512 = 210000
+
+	// 允许的最大迭代次数，防止保存或传入的哈希字符串使用过大的迭代次数消耗CPU
+	MaxIterations = 10000000
+
+	// 哈希(派生密钥)的最小长度(字节)，防止截断的哈希字符串降低校验强度
+	MinHashLength = 16
+)
+
+// 基于PBKDF2-HMAC的密码哈希，结果以PHC字符串格式保存，如：
+// $pbkdf2-sha256$i=600000$<salt>$<hash>
+type Pbkdf2 struct {
+	Algorithm  uint64 // hash.SHA256(default) or hash.SHA512
+	Iterations int    // 迭代次数，默认按哈希算法取推荐值
+	SaltLength int    // 盐长度(字节)，默认16
+	KeyLength  int    // 派生密钥长度(字节)，默认为哈希摘要长度，不能小于MinHashLength
+}
+
+// 生成密码哈希字符串
+func (s *Pbkdf2) Hash(password string) (string, error) {
+	id, h, err := s.algorithm(s.Algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	salt := make([]byte, s.saltLength())
+	_, err = io.ReadFull(rand.Reader, salt)
+	if err != nil {
+		return "", err
+	}
+
+	iterations := s.iterations()
+	if iterations > MaxIterations {
+		return "", fmt.Errorf("invalid iterations: %d, max %d", iterations, MaxIterations)
+	}
+	keyLength := s.keyLength(h)
+	if keyLength < MinHashLength {
+		return "", fmt.Errorf("invalid key length: %d, min %d", keyLength, MinHashLength)
+	}
+	key, err := hash.Pbkdf2(h, []byte(password), salt, iterations, keyLength)
+	if err != nil {
+		return "", err
+	}
+
+	phc := &Phc{
+		Id: id,
+		Params: []PhcParam{
+			{Name: "i", Value: strconv.Itoa(iterations)},
+		},
+		Salt: salt,
+		Hash: key,
+	}
+
+	return phc.String(), nil
+}
+
+// 校验密码是否与哈希字符串匹配(常量时间比较)
+func (s *Pbkdf2) Verify(password, encoded string) (bool, error) {
+	phc, h, iterations, err := s.parse(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	key, err := hash.Pbkdf2(h, []byte(password), phc.Salt, iterations, len(phc.Hash))
+	if err != nil {
+		return false, err
+	}
+
+	return subtle.ConstantTimeCompare(key, phc.Hash) == 1, nil
+}
+
+// 判断已保存的哈希是否使用了过时的参数，需要在下次登录成功后重新生成
+func (s *Pbkdf2) NeedsRehash(encoded string) bool {
+	phc, h, iterations, err := s.parse(encoded)
+	if err != nil {
+		return true
+	}
+
+	id, _, err := s.algorithm(s.Algorithm)
+	if err != nil || phc.Id != id {
+		return true
+	}
+	if iterations < s.iterations() {
+		return true
+	}
+	if len(phc.Salt) < s.saltLength() {
+		return true
+	}
+	if len(phc.Hash) != s.keyLength(h) {
+		return true
+	}
+
+	return false
+}
+
+func (s *Pbkdf2) parse(encoded string) (*Phc, hash.Hash, int, error) {
+	phc := &Phc{}
+	err := phc.Parse(encoded)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if !strings.HasPrefix(phc.Id, "pbkdf2-") {
+		return nil, nil, 0, fmt.Errorf("not support algorithm: %s", phc.Id)
+	}
+
+	var h hash.Hash
+	switch phc.Id {
+	case "pbkdf2-sha256":
+		h = &hash.Sha256{}
+	case "pbkdf2-sha512":
+		h = &hash.Sha512{}
+	default:
+		return nil, nil, 0, fmt.Errorf("not support algorithm: %s", phc.Id)
+	}
+
+	iterations, err := phc.IntParam("i")
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if iterations < 1 || iterations > MaxIterations {
+		return nil, nil, 0, fmt.Errorf("invalid iterations: %d", iterations)
+	}
+	if len(phc.Salt) < 1 || len(phc.Hash) < 1 {
+		return nil, nil, 0, fmt.Errorf("invalid phc string: missing salt or hash")
+	}
+	if len(phc.Hash) < MinHashLength {
+		return nil, nil, 0, fmt.Errorf("invalid phc hash length: %d, min %d", len(phc.Hash), MinHashLength)
+	}
+
+	return phc, h, iterations, nil
+}
+
+func (s *Pbkdf2) algorithm(format uint64) (string, hash.Hash, error) {
+	if format == 0 || format == hash.SHA256 {
+		return "pbkdf2-sha256", &hash.Sha256{}, nil
+	} else if format == hash.SHA512 {
+		return "pbkdf2-sha512", &hash.Sha512{}, nil
+	}
+
+	return "", nil, fmt.Errorf("not support hash: %d", format)
+}
+
+func (s *Pbkdf2) iterations() int {
+	if s.Iterations > 0 {
+		return s.Iterations
+	}
+	if s.Algorithm == hash.SHA512 {
+		return DefaultIterationsSha512
+	}
+
+	return DefaultIterationsSha256
+}
+
+func (s *Pbkdf2) saltLength() int {
+	if s.SaltLength > 0 {
+		return s.SaltLength
+	}
+
+	return DefaultSaltLength
+}
+
+func (s *Pbkdf2) keyLength(h hash.Hash) int {
+	if s.KeyLength > 0 {
+		return s.KeyLength
+	}
+
+	return h.Type().Size()
+}
@@ -0,0 +1,135 @@
+package password
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PHC字符串格式，参考：https://github.com/P-H-C/phc-string-format
+// 格式：$<id>[$v=<version>][$<param>=<value>(,<param>=<value>)*][$<salt>[$<hash>]]
+// salt及hash使用不带补齐的标准base64编码
+type Phc struct {
+	Id     string
+	Params []PhcParam
+	Salt   []byte
+	Hash   []byte
+}
+
+type PhcParam struct {
+	Name  string
+	Value string
+}
+
+func (s *Phc) Param(name string) (string, bool) {
+	for _, p := range s.Params {
+		if p.Name == name {
+			return p.Value, true
+		}
+	}
+
+	return "", false
+}
+
+func (s *Phc) IntParam(name string) (int, error) {
+	val, ok := s.Param(name)
+	if !ok {
+		return 0, fmt.Errorf("parameter '%s' not found", name)
+	}
+	v, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, fmt.Errorf("invalid parameter '%s': %v", name, err)
+	}
+
+	return v, nil
+}
+
+func (s *Phc) String() string {
+	sb := &strings.Builder{}
+	sb.WriteString("$")
+	sb.WriteString(s.Id)
+	if len(s.Params) > 0 {
+		sb.WriteString("$")
+		for i, p := range s.Params {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			sb.WriteString(p.Name)
+			sb.WriteString("=")
+			sb.WriteString(p.Value)
+		}
+	}
+	if s.Salt != nil {
+		sb.WriteString("$")
+		sb.WriteString(base64.RawStdEncoding.EncodeToString(s.Salt))
+		if s.Hash != nil {
+			sb.WriteString("$")
+			sb.WriteString(base64.RawStdEncoding.EncodeToString(s.Hash))
+		}
+	}
+
+	return sb.String()
+}
+
+func (s *Phc) Parse(val string) error {
+	if !strings.HasPrefix(val, "$") {
+		return fmt.Errorf("invalid phc string: missing '$' prefix")
+	}
+	fields := strings.Split(val[1:], "$")
+	if len(fields[0]) < 1 {
+		return fmt.Errorf("invalid phc string: missing id")
+	}
+	s.Id = fields[0]
+	s.Params = make([]PhcParam, 0)
+	s.Salt = nil
+	s.Hash = nil
+	fields = fields[1:]
+
+	// 版本
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "v=") {
+		s.Params = append(s.Params, PhcParam{Name: "v", Value: fields[0][2:]})
+		fields = fields[1:]
+	}
+
+	// 参数
+	if len(fields) > 0 && strings.Contains(fields[0], "=") {
+		for _, item := range strings.Split(fields[0], ",") {
+			kv := strings.SplitN(item, "=", 2)
+			if len(kv) != 2 || len(kv[0]) < 1 {
+				return fmt.Errorf("invalid phc parameter: %s", item)
+			}
+			s.Params = append(s.Params, PhcParam{Name: kv[0], Value: kv[1]})
+		}
+		fields = fields[1:]
+	}
+
+	if len(fields) > 0 {
+		salt, err := decodeB64(fields[0])
+		if err != nil {
+			return fmt.Errorf("invalid phc salt: %v", err)
+		}
+		s.Salt = salt
+		fields = fields[1:]
+	}
+	if len(fields) > 0 {
+		hash, err := decodeB64(fields[0])
+		if err != nil {
+			return fmt.Errorf("invalid phc hash: %v", err)
+		}
+		s.Hash = hash
+		fields = fields[1:]
+	}
+	if len(fields) > 0 {
+		return fmt.Errorf("invalid phc string: too many fields")
+	}
+
+	return nil
+}
+
+// 兼容passlib使用的变体('.'代替'+')及带补齐的编码
+func decodeB64(val string) ([]byte, error) {
+	val = strings.TrimRight(strings.Replace(val, ".", "+", -1), "=")
+
+	return base64.RawStdEncoding.DecodeString(val)
+}
@@ -0,0 +1,90 @@
+package password
+
+import (
+	"encoding/hex"
+	"github.com/csby/security/hash"
+	"testing"
+)
+
+func TestPbkdf2_Vector(t *testing.T) {
+	// RFC 7914 11. Test Vectors for PBKDF2 with HMAC-SHA-256
+	key, err := hash.Pbkdf2(&hash.Sha256{}, []byte("passwd"), []byte("salt"), 1, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc" +
+		"49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"
+	if hex.EncodeToString(key) != expected {
+		t.Errorf("expected %s, got %x", expected, key)
+	}
+}
+
+func TestPbkdf2_Hash(t *testing.T) {
+	p := &Pbkdf2{Iterations: 1000}
+	encoded, err := p.Hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(encoded)
+
+	ok, err := p.Verify("secret", encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Error("verify should succeed with correct password")
+	}
+	ok, err = p.Verify("Secret", encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Error("verify should fail with incorrect password")
+	}
+
+	if p.NeedsRehash(encoded) {
+		t.Error("hash with current parameters should not need rehash")
+	}
+	if !(&Pbkdf2{Iterations: 2000}).NeedsRehash(encoded) {
+		t.Error("hash with fewer iterations should need rehash")
+	}
+	if !(&Pbkdf2{Algorithm: hash.SHA512, Iterations: 1000}).NeedsRehash(encoded) {
+		t.Error("hash with different algorithm should need rehash")
+	}
+}
+
+func TestPbkdf2_Verify(t *testing.T) {
+	// python: hashlib.pbkdf2_hmac("sha256", b"password", b"salt", 1000)
+	p := &Pbkdf2{}
+	ok, err := p.Verify("password", "$pbkdf2-sha256$i=1000$c2FsdA$YywoEuRtRgQQK6dhjp1tfS+BKPYma0oDJk0qBGC33LM")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Error("verify should succeed")
+	}
+}
+
+func TestPbkdf2_VerifyMaxIterations(t *testing.T) {
+	p := &Pbkdf2{}
+	_, err := p.Verify("password", "$pbkdf2-sha256$i=2000000000$c2FsdA$YywoEuRtRgQQK6dhjp1tfS+BKPYma0oDJk0qBGC33LM")
+	if err == nil {
+		t.Error("verify should fail with too many iterations")
+	}
+	if !p.NeedsRehash("$pbkdf2-sha256$i=2000000000$c2FsdA$YywoEuRtRgQQK6dhjp1tfS+BKPYma0oDJk0qBGC33LM") {
+		t.Error("hash with too many iterations should need rehash")
+	}
+}
+
+func TestPbkdf2_VerifyShortHash(t *testing.T) {
+	// 截断为8字节的哈希
+	p := &Pbkdf2{}
+	_, err := p.Verify("password", "$pbkdf2-sha256$i=1000$c2FsdA$YywoEuRtRgQ")
+	if err == nil {
+		t.Error("verify should fail with truncated hash")
+	}
+	_, err = (&Pbkdf2{Iterations: 1000, KeyLength: 8}).Hash("password")
+	if err == nil {
+		t.Error("hash should fail with short key length")
+	}
+}
@@ -1,7 +1,9 @@
 package pkcs12
 
 import (
+	"bytes"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rsa"
 	"crypto/sha1"
 	"crypto/x509"
@@ -10,6 +12,7 @@ import (
 	"encoding/hex"
 	"encoding/pem"
 	"errors"
+	"github.com/csby/security/encoding"
 	"io"
 )
 
@@ -87,11 +90,19 @@ const (
 )
 
 // unmarshal calls asn1.Unmarshal, but also returns an error if there is any
-// trailing data after unmarshaling.
+// trailing data after unmarshaling. BER input (e.g. indefinite lengths) is
+// converted to DER and retried.
 func unmarshal(in []byte, out interface{}) error {
 	trailing, err := asn1.Unmarshal(in, out)
 	if err != nil {
-		return err
+		der, berErr := (&encoding.Asn1Parser{}).ToDer(in)
+		if berErr != nil || bytes.Equal(der, in) {
+			return err
+		}
+		trailing, err = asn1.Unmarshal(der, out)
+		if err != nil {
+			return err
+		}
 	}
 	if len(trailing) != 0 {
 		return errors.New("pkcs12: trailing data found")
@@ -161,6 +172,11 @@ func convertBag(bag *safeBag, password []byte) (*pem.Block, error) {
 			if err != nil {
 				return nil, err
 			}
+		case ed25519.PrivateKey:
+			block.Bytes, err = x509.MarshalPKCS8PrivateKey(key)
+			if err != nil {
+				return nil, err
+			}
 		default:
 			return nil, errors.New("found unknown private key type in PKCS#8 wrapping")
 		}
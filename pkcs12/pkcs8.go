@@ -2,6 +2,7 @@ package pkcs12
 
 import (
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rsa"
 	"crypto/x509"
@@ -17,8 +18,9 @@ type pkcs8 struct { // Duplicated from x509 package
 }
 
 var ( // Duplicated from x509 package
-	oidPublicKeyRSA   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
-	oidPublicKeyECDSA = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidPublicKeyRSA     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidPublicKeyECDSA   = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidPublicKeyEd25519 = asn1.ObjectIdentifier{1, 3, 101, 112}
 )
 
 var ( // Duplicated from x509 package
@@ -66,8 +68,14 @@ func marshalPKCS8PrivateKey(key interface{}) (der []byte, err error) {
 		if privKey.PrivateKey, err = x509.MarshalECPrivateKey(key); err != nil {
 			return nil, errors.New("pkcs12: failed to embed EC private key in PKCS#8: " + err.Error())
 		}
+	case ed25519.PrivateKey:
+		privKey.Algo.Algorithm = oidPublicKeyEd25519
+		// RFC 8410: CurvePrivateKey ::= OCTET STRING (seed)
+		if privKey.PrivateKey, err = asn1.Marshal(key.Seed()); err != nil {
+			return nil, errors.New("pkcs12: failed to embed Ed25519 private key in PKCS#8: " + err.Error())
+		}
 	default:
-		return nil, errors.New("pkcs12: only RSA and ECDSA private keys supported")
+		return nil, errors.New("pkcs12: only RSA, ECDSA and Ed25519 private keys supported")
 	}
 	return asn1.Marshal(privKey)
 }
@@ -0,0 +1,70 @@
+package shamir
+
+// GF(2^8)运算，约化多项式x^8 + x^4 + x^3 + x + 1(0x11b)，生成元0x03
+var (
+	gfExp [510]byte
+	gfLog [256]byte
+)
+
+func init() {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		gfExp[i] = x
+		gfExp[i+255] = x
+		gfLog[x] = byte(i)
+		// x = x * 3
+		x2 := x << 1
+		if x&0x80 != 0 {
+			x2 ^= 0x1b
+		}
+		x ^= x2
+	}
+}
+
+func gfAdd(a, b byte) byte {
+	return a ^ b
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+// b不能为0
+func gfDiv(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+
+	return gfExp[int(gfLog[a])+255-int(gfLog[b])]
+}
+
+// 按秦九韶算法计算多项式在x处的值，coefficients[0]为常数项
+func gfEval(coefficients []byte, x byte) byte {
+	y := byte(0)
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		y = gfAdd(gfMul(y, x), coefficients[i])
+	}
+
+	return y
+}
+
+// 拉格朗日插值计算多项式在0处的值
+func gfInterpolate(xs, ys []byte) byte {
+	y := byte(0)
+	for i := range xs {
+		basis := byte(1)
+		for j := range xs {
+			if i == j {
+				continue
+			}
+			basis = gfMul(basis, gfDiv(xs[j], gfAdd(xs[j], xs[i])))
+		}
+		y = gfAdd(y, gfMul(ys[i], basis))
+	}
+
+	return y
+}
@@ -0,0 +1,210 @@
+package shamir
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+	"github.com/csby/security/encoding"
+	"github.com/csby/security/hash"
+	"hash/crc32"
+	"io"
+	"strings"
+)
+
+const (
+	shareVersion     = 1
+	shareHeaderSize  = 7 // 版本(1) + 标识(4) + 门限(1) + 序号(1)
+	shareCheckSize   = 4 // CRC32
+	secretDigestSize = 8 // 秘密SHA-256摘要前8字节，用于恢复后校验
+	shareGroupSize   = 5
+)
+
+// 秘密份额
+type Share struct {
+	Id        uint32 // 分割标识，同一次分割产生的份额相同
+	Threshold int    // 恢复秘密所需的最少份额数
+	Index     byte   // 份额序号(1~255)
+	Data      []byte // 份额数据，长度为秘密长度+8
+}
+
+// Shamir秘密分割(GF(256))，将secret分割为count份，任意threshold份可恢复秘密，少于threshold份无法获得秘密的任何信息
+// 参考：Shamir, How to Share a Secret (1979)
+func Split(secret []byte, threshold, count int) ([]*Share, error) {
+	if len(secret) < 1 {
+		return nil, fmt.Errorf("invalid secret: empty")
+	}
+	if threshold < 2 || threshold > 255 {
+		return nil, fmt.Errorf("invalid threshold: %d", threshold)
+	}
+	if count < threshold || count > 255 {
+		return nil, fmt.Errorf("invalid count: %d", count)
+	}
+
+	digest, err := secretDigest(secret)
+	if err != nil {
+		return nil, err
+	}
+	payload := append(append([]byte{}, secret...), digest...)
+
+	idData := make([]byte, 4)
+	_, err = io.ReadFull(rand.Reader, idData)
+	if err != nil {
+		return nil, err
+	}
+	id := binary.BigEndian.Uint32(idData)
+
+	shares := make([]*Share, count)
+	for i := range shares {
+		shares[i] = &Share{
+			Id:        id,
+			Threshold: threshold,
+			Index:     byte(i + 1),
+			Data:      make([]byte, len(payload)),
+		}
+	}
+
+	// 每个字节使用独立的随机多项式，常数项为秘密字节
+	coefficients := make([]byte, threshold)
+	for offset, b := range payload {
+		_, err = io.ReadFull(rand.Reader, coefficients[1:])
+		if err != nil {
+			return nil, err
+		}
+		coefficients[0] = b
+		for _, share := range shares {
+			share.Data[offset] = gfEval(coefficients, share.Index)
+		}
+	}
+	for i := range coefficients {
+		coefficients[i] = 0
+	}
+
+	return shares, nil
+}
+
+// 使用不少于门限数的份额恢复秘密，并校验秘密的完整性
+func Combine(shares []*Share) ([]byte, error) {
+	if len(shares) < 1 {
+		return nil, fmt.Errorf("invalid shares: empty")
+	}
+	first := shares[0]
+	if first == nil {
+		return nil, fmt.Errorf("invalid share: nil")
+	}
+	if len(first.Data) <= secretDigestSize {
+		return nil, fmt.Errorf("invalid share data length: %d", len(first.Data))
+	}
+
+	used := make(map[byte]bool)
+	selected := make([]*Share, 0, first.Threshold)
+	for _, share := range shares {
+		if share == nil {
+			return nil, fmt.Errorf("invalid share: nil")
+		}
+		if share.Id != first.Id {
+			return nil, fmt.Errorf("share %d does not belong to the same split", share.Index)
+		}
+		if share.Threshold != first.Threshold || len(share.Data) != len(first.Data) {
+			return nil, fmt.Errorf("share %d mismatch", share.Index)
+		}
+		if share.Index == 0 {
+			return nil, fmt.Errorf("invalid share index: 0")
+		}
+		if used[share.Index] {
+			continue
+		}
+		used[share.Index] = true
+		if len(selected) < first.Threshold {
+			selected = append(selected, share)
+		}
+	}
+	if len(selected) < first.Threshold {
+		return nil, fmt.Errorf("not enough shares: %d of %d", len(selected), first.Threshold)
+	}
+
+	xs := make([]byte, len(selected))
+	ys := make([]byte, len(selected))
+	for i, share := range selected {
+		xs[i] = share.Index
+	}
+	payload := make([]byte, len(first.Data))
+	for offset := range payload {
+		for i, share := range selected {
+			ys[i] = share.Data[offset]
+		}
+		payload[offset] = gfInterpolate(xs, ys)
+	}
+
+	secret := payload[:len(payload)-secretDigestSize]
+	digest, err := secretDigest(secret)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(digest, payload[len(secret):]) != 1 {
+		return nil, fmt.Errorf("secret integrity check failed")
+	}
+
+	return secret, nil
+}
+
+// 转换为便于抄写的字符串：Crockford Base32编码，每5个字符以'-'分隔，包含CRC32校验
+func (s *Share) ToString() string {
+	data := make([]byte, shareHeaderSize, shareHeaderSize+len(s.Data)+shareCheckSize)
+	data[0] = shareVersion
+	binary.BigEndian.PutUint32(data[1:5], s.Id)
+	data[5] = byte(s.Threshold)
+	data[6] = s.Index
+	data = append(data, s.Data...)
+	check := make([]byte, shareCheckSize)
+	binary.BigEndian.PutUint32(check, crc32.ChecksumIEEE(data))
+	data = append(data, check...)
+
+	text := (&encoding.Base32{Crockford: true}).EncodeToString(data)
+	groups := make([]string, 0, len(text)/shareGroupSize+1)
+	for len(text) > shareGroupSize {
+		groups = append(groups, text[:shareGroupSize])
+		text = text[shareGroupSize:]
+	}
+	groups = append(groups, text)
+
+	return strings.Join(groups, "-")
+}
+
+// 从字符串加载份额，忽略大小写、空白及'-'，抄写错误时返回校验失败
+func (s *Share) FromString(val string) error {
+	val = strings.Join(strings.Fields(val), "")
+	data, err := (&encoding.Base32{Crockford: true}).DecodeFromString(val)
+	if err != nil {
+		return fmt.Errorf("invalid share: %v", err)
+	}
+	if len(data) <= shareHeaderSize+shareCheckSize+secretDigestSize {
+		return fmt.Errorf("invalid share: too short")
+	}
+	body := data[:len(data)-shareCheckSize]
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(data[len(body):]) {
+		return fmt.Errorf("invalid share: checksum mismatch")
+	}
+	if body[0] != shareVersion {
+		return fmt.Errorf("invalid share: unsupported version %d", body[0])
+	}
+	if body[5] < 2 || body[6] == 0 {
+		return fmt.Errorf("invalid share: bad header")
+	}
+
+	s.Id = binary.BigEndian.Uint32(body[1:5])
+	s.Threshold = int(body[5])
+	s.Index = body[6]
+	s.Data = append([]byte{}, body[shareHeaderSize:]...)
+
+	return nil
+}
+
+func secretDigest(secret []byte) ([]byte, error) {
+	sum, err := (&hash.Sha256{}).Hash(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return sum[:secretDigestSize], nil
+}
@@ -0,0 +1,119 @@
+package shamir
+
+import (
+	"bytes"
+	"crypto/rand"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shares, err := Split(secret, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shares) != 5 {
+		t.Fatalf("expected 5 shares, got %d", len(shares))
+	}
+
+	// 任意3份均可恢复
+	for i := 0; i < 5; i++ {
+		for j := i + 1; j < 5; j++ {
+			for k := j + 1; k < 5; k++ {
+				combined, err := Combine([]*Share{shares[k], shares[i], shares[j]})
+				if err != nil {
+					t.Fatal(i, j, k, err)
+				}
+				if !bytes.Equal(combined, secret) {
+					t.Error(i, j, k, "secret mismatch")
+				}
+			}
+		}
+	}
+
+	_, err = Combine(shares[:2])
+	if err == nil {
+		t.Error("combine should fail with not enough shares")
+	}
+	_, err = Combine([]*Share{shares[0], shares[0], shares[1]})
+	if err == nil {
+		t.Error("combine should fail with duplicate shares")
+	}
+
+	tampered := *shares[2]
+	tampered.Data = append([]byte{}, shares[2].Data...)
+	tampered.Data[0] ^= 0x01
+	_, err = Combine([]*Share{shares[0], shares[1], &tampered})
+	if err == nil {
+		t.Error("combine should fail with tampered share")
+	}
+
+	others, err := Split(secret, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Combine([]*Share{shares[0], shares[1], others[2]})
+	if err == nil {
+		t.Error("combine should fail with shares from different split")
+	}
+}
+
+func TestShare_ToString(t *testing.T) {
+	secret := []byte("root ca private key")
+	shares, err := Split(secret, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := shares[1].ToString()
+	t.Log(text)
+
+	// 忽略大小写及空白
+	share := &Share{}
+	err = share.FromString(strings.ToLower(strings.Replace(text, "-", " ", 3)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if share.Id != shares[1].Id || share.Index != 2 || share.Threshold != 2 || !bytes.Equal(share.Data, shares[1].Data) {
+		t.Error("share mismatch")
+	}
+	combined, err := Combine([]*Share{shares[0], share})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(combined, secret) {
+		t.Error("secret mismatch")
+	}
+
+	// 抄写错误
+	typo := []byte(text)
+	if typo[10] == 'A' {
+		typo[10] = 'B'
+	} else {
+		typo[10] = 'A'
+	}
+	err = share.FromString(string(typo))
+	if err == nil {
+		t.Error("decode should fail with transcription error")
+	}
+}
+
+func TestGf256(t *testing.T) {
+	for a := 1; a < 256; a++ {
+		for b := 1; b < 256; b++ {
+			if gfDiv(gfMul(byte(a), byte(b)), byte(b)) != byte(a) {
+				t.Fatalf("gf256 %d * %d / %d mismatch", a, b, b)
+			}
+		}
+	}
+	// AES规范示例：0x57 * 0x83 = 0xc1
+	if gfMul(0x57, 0x83) != 0xc1 {
+		t.Errorf("gf256 0x57 * 0x83 expected 0xc1, got %#x", gfMul(0x57, 0x83))
+	}
+}